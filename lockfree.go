@@ -0,0 +1,130 @@
+package fifo
+
+import "sync/atomic"
+
+// cacheLineSize is the assumed CPU cache line size, used to pad hot fields so that independent
+// goroutines touching different cells or counters don't contend over the same cache line.
+const cacheLineSize = 64
+
+// lfCell is a single slot in a LockFreeQueue's ring buffer. sequence is padded onto its own cache
+// line since it is the field enqueue/dequeue spin-CAS on; value is left unpadded since its size
+// depends on T and is only ever touched by the single goroutine that currently owns the slot.
+type lfCell[T any] struct {
+	sequence atomic.Uint64
+	_        [cacheLineSize - 8]byte
+	value    T
+}
+
+// LockFreeQueue is a bounded, multi-producer multi-consumer FIFO queue backed by a power-of-two
+// sized ring buffer, implementing the Vyukov MPMC algorithm. Unlike Queue[T], it never takes a
+// mutex: TryEnqueue and TryDequeue make progress purely via atomic compare-and-swap, which makes
+// it significantly cheaper under contention at the cost of the richer API (no blocking variants,
+// no Resize, no Close) that Queue[T] offers.
+type LockFreeQueue[T any] struct {
+	buffer []lfCell[T]
+	mask   uint64
+
+	_    [cacheLineSize]byte
+	head atomic.Uint64
+	_    [cacheLineSize - 8]byte
+	tail atomic.Uint64
+}
+
+// NewLockFreeQueue creates a new LockFreeQueue with room for at least capacity items, or panics
+// if the capacity is not positive. The actual capacity is rounded up to the next power of two, and
+// is at least 2.
+func NewLockFreeQueue[T any](capacity int) *LockFreeQueue[T] {
+	if capacity <= 0 {
+		panic(ErrCapacityNotPositive)
+	}
+
+	size := nextPowerOfTwo(uint64(capacity))
+	if size < 2 {
+		// With a single cell, a full slot's sequence (pos+1) equals the next enqueue position, so
+		// TryEnqueue would overwrite the unread item instead of reporting ErrQueueFull.
+		size = 2
+	}
+	q := &LockFreeQueue[T]{
+		buffer: make([]lfCell[T], size),
+		mask:   size - 1,
+	}
+	for i := range q.buffer {
+		q.buffer[i].sequence.Store(uint64(i))
+	}
+
+	return q
+}
+
+// Cap returns the queue's capacity, which is always a power of two.
+func (q *LockFreeQueue[T]) Cap() int {
+	return len(q.buffer)
+}
+
+// TryEnqueue attempts to add an item to the end of the queue. If the queue is full, ErrQueueFull
+// is returned immediately.
+func (q *LockFreeQueue[T]) TryEnqueue(item T) error {
+	var cell *lfCell[T]
+	pos := q.tail.Load()
+
+	for {
+		cell = &q.buffer[pos&q.mask]
+		seq := cell.sequence.Load()
+
+		switch diff := int64(seq) - int64(pos); {
+		case diff == 0:
+			if q.tail.CompareAndSwap(pos, pos+1) {
+				cell.value = item
+				cell.sequence.Store(pos + 1)
+				return nil
+			}
+			pos = q.tail.Load()
+		case diff < 0:
+			return ErrQueueFull
+		default:
+			pos = q.tail.Load()
+		}
+	}
+}
+
+// TryDequeue attempts to remove and returns the item at the front of the queue. If the queue is
+// empty, ErrQueueEmpty is returned immediately.
+func (q *LockFreeQueue[T]) TryDequeue() (T, error) {
+	var zero T
+	var cell *lfCell[T]
+	pos := q.head.Load()
+
+	for {
+		cell = &q.buffer[pos&q.mask]
+		seq := cell.sequence.Load()
+
+		switch diff := int64(seq) - int64(pos+1); {
+		case diff == 0:
+			if q.head.CompareAndSwap(pos, pos+1) {
+				item := cell.value
+				cell.value = zero // Clear the reference to allow garbage collection
+				cell.sequence.Store(pos + q.mask + 1)
+				return item, nil
+			}
+			pos = q.head.Load()
+		case diff < 0:
+			return zero, ErrQueueEmpty
+		default:
+			pos = q.head.Load()
+		}
+	}
+}
+
+// nextPowerOfTwo returns the smallest power of two greater than or equal to n.
+func nextPowerOfTwo(n uint64) uint64 {
+	if n <= 1 {
+		return 1
+	}
+	n--
+	n |= n >> 1
+	n |= n >> 2
+	n |= n >> 4
+	n |= n >> 8
+	n |= n >> 16
+	n |= n >> 32
+	return n + 1
+}
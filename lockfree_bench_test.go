@@ -0,0 +1,68 @@
+package fifo
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// runQueueBenchmark drives count producer goroutines and count consumer goroutines against a
+// queue implementing TryEnqueue/TryDequeue, spinning on ErrQueueFull/ErrQueueEmpty.
+func runQueueBenchmark(b *testing.B, count int, enqueue func(int) error, dequeue func() error) {
+	b.Helper()
+
+	var wg sync.WaitGroup
+	perRoutine := b.N / count
+	if perRoutine == 0 {
+		perRoutine = 1
+	}
+
+	b.ResetTimer()
+
+	for p := 0; p < count; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perRoutine; i++ {
+				for enqueue(i) != nil {
+				}
+			}
+		}()
+	}
+	for c := 0; c < count; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perRoutine; i++ {
+				for dequeue() != nil {
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func BenchmarkQueue_MPMC(b *testing.B) {
+	for _, count := range []int{1, 2, 4, 8} {
+		b.Run(strconv.Itoa(count), func(b *testing.B) {
+			q := New[int](1024)
+			runQueueBenchmark(b, count,
+				func(i int) error { return q.TryEnqueue(i) },
+				func() error { _, err := q.TryDequeue(); return err },
+			)
+		})
+	}
+}
+
+func BenchmarkLockFreeQueue_MPMC(b *testing.B) {
+	for _, count := range []int{1, 2, 4, 8} {
+		b.Run(strconv.Itoa(count), func(b *testing.B) {
+			q := NewLockFreeQueue[int](1024)
+			runQueueBenchmark(b, count,
+				func(i int) error { return q.TryEnqueue(i) },
+				func() error { _, err := q.TryDequeue(); return err },
+			)
+		})
+	}
+}
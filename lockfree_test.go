@@ -0,0 +1,103 @@
+package fifo
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLockFreeQueue_TryEnqueueDequeue(t *testing.T) {
+	q := NewLockFreeQueue[int](4)
+
+	for i := 1; i <= 4; i++ {
+		if err := q.TryEnqueue(i); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := q.TryEnqueue(5); err != ErrQueueFull {
+		t.Fatalf("expected ErrQueueFull, got: %v", err)
+	}
+
+	for i := 1; i <= 4; i++ {
+		item, err := q.TryDequeue()
+		if err != nil || item != i {
+			t.Fatalf("expected %d, got: %v, err: %v", i, item, err)
+		}
+	}
+
+	if _, err := q.TryDequeue(); err != ErrQueueEmpty {
+		t.Fatalf("expected ErrQueueEmpty, got: %v", err)
+	}
+}
+
+func TestLockFreeQueue_CapRoundsUpToPowerOfTwo(t *testing.T) {
+	q := NewLockFreeQueue[int](5)
+	if q.Cap() != 8 {
+		t.Fatalf("expected cap 8, got: %d", q.Cap())
+	}
+}
+
+func TestLockFreeQueue_EnqueueDequeueWithWraparound(t *testing.T) {
+	q := NewLockFreeQueue[int](2)
+
+	q.TryEnqueue(1)
+	q.TryEnqueue(2)
+	q.TryDequeue()
+	q.TryDequeue()
+	q.TryEnqueue(3)
+	q.TryEnqueue(4)
+
+	item, err := q.TryDequeue()
+	if err != nil || item != 3 {
+		t.Fatalf("expected 3, got: %v, err: %v", item, err)
+	}
+	item, err = q.TryDequeue()
+	if err != nil || item != 4 {
+		t.Fatalf("expected 4, got: %v, err: %v", item, err)
+	}
+}
+
+func TestLockFreeQueue_ConcurrentMPMC(t *testing.T) {
+	const (
+		producers  = 8
+		consumers  = 8
+		perRoutine = 1000
+	)
+
+	q := NewLockFreeQueue[int](64)
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perRoutine; i++ {
+				for q.TryEnqueue(i) == ErrQueueFull {
+					// Spin until room is available.
+				}
+			}
+		}()
+	}
+
+	var received atomic.Int64
+	var cwg sync.WaitGroup
+	for c := 0; c < consumers; c++ {
+		cwg.Add(1)
+		go func() {
+			defer cwg.Done()
+			for received.Load() < producers*perRoutine {
+				if _, err := q.TryDequeue(); err == nil {
+					received.Add(1)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	cwg.Wait()
+
+	if got := received.Load(); got != producers*perRoutine {
+		t.Fatalf("expected %d items received, got: %d", producers*perRoutine, got)
+	}
+}
@@ -0,0 +1,602 @@
+package fifo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultSegmentSize is the segment file size used by OpenPersistentQueue when the caller passes
+// a segmentSize of zero.
+const DefaultSegmentSize int64 = 64 << 20 // 64 MiB
+
+// ErrRecordTooLarge is returned when a single encoded record would not fit in an empty segment
+// file of the configured segment size.
+var ErrRecordTooLarge = errors.New("record larger than segment size")
+
+// Codec encodes and decodes values of type T for storage in a PersistentQueue's segment files.
+type Codec[T any] interface {
+	Encode(v T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// GobCodec is a Codec that serializes values using encoding/gob.
+type GobCodec[T any] struct{}
+
+// Encode implements Codec.
+func (GobCodec[T]) Encode(v T) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode implements Codec.
+func (GobCodec[T]) Decode(data []byte) (T, error) {
+	var v T
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v)
+	return v, err
+}
+
+// JSONCodec is a Codec that serializes values using encoding/json.
+type JSONCodec[T any] struct{}
+
+// Encode implements Codec.
+func (JSONCodec[T]) Encode(v T) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Decode implements Codec.
+func (JSONCodec[T]) Decode(data []byte) (T, error) {
+	var v T
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+// SyncPolicy controls when a PersistentQueue fsyncs its segment and checkpoint files.
+type SyncPolicy struct {
+	kind     syncKind
+	interval time.Duration
+}
+
+type syncKind int
+
+const (
+	syncAlways syncKind = iota
+	syncNever
+	syncOnInterval
+)
+
+// SyncAlways fsyncs after every enqueued record and every checkpoint update. This is the
+// strongest durability guarantee, at the cost of one fsync per operation.
+func SyncAlways() SyncPolicy { return SyncPolicy{kind: syncAlways} }
+
+// SyncNever never fsyncs explicitly, relying on the OS to eventually flush dirty pages. Enqueued
+// records may be lost on a crash, but writes are never blocked on disk latency.
+func SyncNever() SyncPolicy { return SyncPolicy{kind: syncNever} }
+
+// SyncInterval fsyncs at most once every d, batching the fsync cost of a burst of operations.
+func SyncInterval(d time.Duration) SyncPolicy { return SyncPolicy{kind: syncOnInterval, interval: d} }
+
+// recordHeaderSize is the length-prefix plus CRC32 checksum preceding each record's payload.
+const recordHeaderSize = 8
+
+// PersistentQueue is a disk-backed FIFO queue: enqueued items are appended as length-prefixed,
+// CRC32-checksummed records to a directory of fixed-size segment files, so that items survive a
+// process crash or restart. TryEnqueue, TryDequeue and BlockingDequeue follow the same error
+// contract as Queue[T]; there is no BlockingEnqueue, since the queue is never full.
+type PersistentQueue[T any] struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	dir         string
+	codec       Codec[T]
+	segmentSize int64
+	sync        SyncPolicy
+
+	writeSegID int64
+	writeFile  *os.File
+	writeSize  int64
+
+	readSegID int64
+	readFile  *os.File
+	readOff   int64
+
+	checkpointPath string
+	lastSyncTime   time.Time
+
+	len    int
+	closed bool
+}
+
+// OpenPersistentQueue opens (creating if necessary) a persistent queue rooted at dir. If
+// segmentSize is zero, DefaultSegmentSize is used. On open, the tail of the most recent segment
+// is scanned and any trailing partial or corrupt record left by a torn write is truncated away.
+func OpenPersistentQueue[T any](dir string, codec Codec[T], segmentSize int64, policy SyncPolicy) (*PersistentQueue[T], error) {
+	if segmentSize <= 0 {
+		segmentSize = DefaultSegmentSize
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("fifo: create queue directory: %w", err)
+	}
+
+	q := &PersistentQueue[T]{
+		dir:            dir,
+		codec:          codec,
+		segmentSize:    segmentSize,
+		sync:           policy,
+		checkpointPath: filepath.Join(dir, "checkpoint"),
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	segIDs, err := q.listSegments()
+	if err != nil {
+		return nil, err
+	}
+	if len(segIDs) == 0 {
+		segIDs = []int64{0}
+	}
+	q.writeSegID = segIDs[len(segIDs)-1]
+
+	if err := q.recoverTornWrite(q.writeSegID); err != nil {
+		return nil, err
+	}
+
+	readSegID, readOff, err := q.loadCheckpoint(segIDs)
+	if err != nil {
+		return nil, err
+	}
+	q.readSegID = readSegID
+	q.readOff = readOff
+
+	if err := q.openWriteSegment(); err != nil {
+		return nil, err
+	}
+	if err := q.openReadSegment(); err != nil {
+		return nil, err
+	}
+
+	n, err := q.countRemaining(segIDs)
+	if err != nil {
+		return nil, err
+	}
+	q.len = n
+
+	return q, nil
+}
+
+// segmentPath returns the path of the segment file with the given id.
+func (q *PersistentQueue[T]) segmentPath(id int64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d.seg", id))
+}
+
+// listSegments returns the ids of all segment files in q.dir, sorted ascending.
+func (q *PersistentQueue[T]) listSegments() ([]int64, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, fmt.Errorf("fifo: list segments: %w", err)
+	}
+
+	var ids []int64
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".seg" {
+			continue
+		}
+		var id int64
+		if _, err := fmt.Sscanf(e.Name(), "%020d.seg", &id); err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids, nil
+}
+
+// recoverTornWrite validates every record in the given segment from the start, and truncates the
+// file at the first incomplete or CRC-mismatched record, which can only be the result of a crash
+// partway through an append.
+func (q *PersistentQueue[T]) recoverTornWrite(segID int64) error {
+	path := q.segmentPath(segID)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("fifo: open segment %d: %w", segID, err)
+	}
+	defer f.Close()
+
+	var offset int64
+	for {
+		_, n, err := readRecord(f, offset)
+		if err != nil {
+			// A clean EOF means offset already sits at a valid record boundary. Any other
+			// error means the record at offset was torn by a crash mid-write; in both cases
+			// offset is where we truncate.
+			break
+		}
+		offset = n
+	}
+
+	return f.Truncate(offset)
+}
+
+// loadCheckpoint reads the last persisted read position, clamping it to the oldest segment that
+// still exists (an older segment may have been fully consumed and deleted before a crash).
+func (q *PersistentQueue[T]) loadCheckpoint(segIDs []int64) (segID int64, offset int64, err error) {
+	data, err := os.ReadFile(q.checkpointPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return segIDs[0], 0, nil
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("fifo: read checkpoint: %w", err)
+	}
+	if len(data) != 16 {
+		// Torn checkpoint write; fall back to the oldest segment rather than losing data.
+		return segIDs[0], 0, nil
+	}
+
+	segID = int64(binary.BigEndian.Uint64(data[0:8]))
+	offset = int64(binary.BigEndian.Uint64(data[8:16]))
+
+	for _, id := range segIDs {
+		if id >= segID {
+			if id > segID {
+				offset = 0
+			}
+			return id, offset, nil
+		}
+	}
+
+	return segIDs[len(segIDs)-1], 0, nil
+}
+
+// countRemaining counts the records still unread across the read segment and any later segments.
+func (q *PersistentQueue[T]) countRemaining(segIDs []int64) (int, error) {
+	count := 0
+	for _, id := range segIDs {
+		if id < q.readSegID {
+			continue
+		}
+
+		f, err := os.Open(q.segmentPath(id))
+		if err != nil {
+			return 0, fmt.Errorf("fifo: open segment %d: %w", id, err)
+		}
+
+		offset := int64(0)
+		if id == q.readSegID {
+			offset = q.readOff
+		}
+		for {
+			_, n, err := readRecord(f, offset)
+			if err != nil {
+				break
+			}
+			offset = n
+			count++
+		}
+		f.Close()
+	}
+
+	return count, nil
+}
+
+func (q *PersistentQueue[T]) openWriteSegment() error {
+	f, err := os.OpenFile(q.segmentPath(q.writeSegID), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("fifo: open write segment %d: %w", q.writeSegID, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("fifo: stat write segment %d: %w", q.writeSegID, err)
+	}
+
+	q.writeFile = f
+	q.writeSize = info.Size()
+
+	return nil
+}
+
+func (q *PersistentQueue[T]) openReadSegment() error {
+	f, err := os.Open(q.segmentPath(q.readSegID))
+	if err != nil {
+		return fmt.Errorf("fifo: open read segment %d: %w", q.readSegID, err)
+	}
+	q.readFile = f
+	return nil
+}
+
+// TryEnqueue appends item as a new record. It never blocks; it only fails if the queue is closed,
+// the item cannot be encoded, the encoded record is larger than a segment (ErrRecordTooLarge), or
+// the append fails (e.g. disk full).
+func (q *PersistentQueue[T]) TryEnqueue(item T) error {
+	payload, err := q.codec.Encode(item)
+	if err != nil {
+		return fmt.Errorf("fifo: encode item: %w", err)
+	}
+	if int64(len(payload))+recordHeaderSize > q.segmentSize {
+		return ErrRecordTooLarge
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	if q.writeSize+int64(len(payload))+recordHeaderSize > q.segmentSize {
+		if err := q.rollWriteSegmentLocked(); err != nil {
+			return err
+		}
+	}
+
+	if err := writeRecord(q.writeFile, payload); err != nil {
+		return fmt.Errorf("fifo: append record: %w", err)
+	}
+	q.writeSize += int64(len(payload)) + recordHeaderSize
+
+	if err := q.maybeSyncLocked(q.writeFile); err != nil {
+		return err
+	}
+
+	q.len++
+	q.cond.Broadcast()
+
+	return nil
+}
+
+// rollWriteSegmentLocked fsyncs and closes the current write segment and opens the next one. The
+// caller must hold q.mu.
+func (q *PersistentQueue[T]) rollWriteSegmentLocked() error {
+	if err := q.writeFile.Sync(); err != nil {
+		return fmt.Errorf("fifo: sync segment %d: %w", q.writeSegID, err)
+	}
+	if err := q.writeFile.Close(); err != nil {
+		return fmt.Errorf("fifo: close segment %d: %w", q.writeSegID, err)
+	}
+
+	q.writeSegID++
+	return q.openWriteSegment()
+}
+
+// TryDequeue removes and returns the oldest unread item, without blocking. If no item is
+// available, ErrQueueEmpty is returned (or ErrQueueClosed if the queue was also closed).
+func (q *PersistentQueue[T]) TryDequeue() (T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.tryDequeueLocked()
+}
+
+// BlockingDequeue removes and returns the oldest unread item, blocking until one is appended or
+// the queue is closed.
+func (q *PersistentQueue[T]) BlockingDequeue() (T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		item, err := q.tryDequeueLocked()
+		if err != ErrQueueEmpty {
+			return item, err
+		}
+		q.cond.Wait()
+	}
+}
+
+func (q *PersistentQueue[T]) tryDequeueLocked() (T, error) {
+	var zero T
+
+	if q.readFile == nil {
+		// The read segment was already closed by a previous call once the queue drained.
+		return zero, ErrQueueClosed
+	}
+
+	payload, nextOff, err := readRecord(q.readFile, q.readOff)
+	for errors.Is(err, io.EOF) && q.readSegID != q.writeSegID {
+		// The read segment was drained before a later append rolled the writer onto a new
+		// segment; move on to the next segment and read from there.
+		if err := q.advanceIfSegmentConsumedLocked(); err != nil {
+			return zero, err
+		}
+		payload, nextOff, err = readRecord(q.readFile, q.readOff)
+	}
+	if errors.Is(err, io.EOF) {
+		if q.closed {
+			q.readFile.Close()
+			q.readFile = nil
+			return zero, ErrQueueClosed
+		}
+		return zero, ErrQueueEmpty
+	}
+	if err != nil {
+		return zero, fmt.Errorf("fifo: read record: %w", err)
+	}
+
+	item, err := q.codec.Decode(payload)
+	if err != nil {
+		return zero, fmt.Errorf("fifo: decode item: %w", err)
+	}
+
+	q.readOff = nextOff
+	q.len--
+
+	if err := q.checkpointLocked(); err != nil {
+		return zero, err
+	}
+	if err := q.advanceIfSegmentConsumedLocked(); err != nil {
+		return zero, err
+	}
+
+	return item, nil
+}
+
+// advanceIfSegmentConsumedLocked moves the read cursor to the next segment, deleting the old
+// segment file, once the current read segment is no longer the active write segment and has been
+// fully drained.
+func (q *PersistentQueue[T]) advanceIfSegmentConsumedLocked() error {
+	if q.readSegID == q.writeSegID {
+		return nil
+	}
+
+	info, err := q.readFile.Stat()
+	if err != nil {
+		return fmt.Errorf("fifo: stat segment %d: %w", q.readSegID, err)
+	}
+	if q.readOff < info.Size() {
+		return nil
+	}
+
+	nextID := q.readSegID + 1
+	f, err := os.Open(q.segmentPath(nextID))
+	if err != nil {
+		return fmt.Errorf("fifo: open segment %d: %w", nextID, err)
+	}
+
+	oldSegID, oldFile := q.readSegID, q.readFile
+	q.readSegID, q.readFile, q.readOff = nextID, f, 0
+
+	if err := q.checkpointLocked(); err != nil {
+		return err
+	}
+
+	oldFile.Close()
+	return os.Remove(q.segmentPath(oldSegID))
+}
+
+// checkpointLocked persists the current read position so it can be recovered after a restart.
+func (q *PersistentQueue[T]) checkpointLocked() error {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[0:8], uint64(q.readSegID))
+	binary.BigEndian.PutUint64(buf[8:16], uint64(q.readOff))
+
+	tmp := q.checkpointPath + ".tmp"
+	if err := os.WriteFile(tmp, buf[:], 0o644); err != nil {
+		return fmt.Errorf("fifo: write checkpoint: %w", err)
+	}
+
+	if err := q.maybeSyncCheckpointLocked(tmp); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, q.checkpointPath)
+}
+
+// maybeSyncLocked fsyncs f according to q.sync.
+func (q *PersistentQueue[T]) maybeSyncLocked(f *os.File) error {
+	switch q.sync.kind {
+	case syncNever:
+		return nil
+	case syncOnInterval:
+		if time.Since(q.lastSyncTime) < q.sync.interval {
+			return nil
+		}
+	}
+
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("fifo: sync: %w", err)
+	}
+	q.lastSyncTime = time.Now()
+
+	return nil
+}
+
+// maybeSyncCheckpointLocked fsyncs the checkpoint's temp file according to q.sync before it is
+// renamed into place.
+func (q *PersistentQueue[T]) maybeSyncCheckpointLocked(tmp string) error {
+	if q.sync.kind == syncNever {
+		return nil
+	}
+
+	f, err := os.OpenFile(tmp, os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("fifo: open checkpoint for sync: %w", err)
+	}
+	defer f.Close()
+
+	return q.maybeSyncLocked(f)
+}
+
+// Len returns the number of items that have been enqueued but not yet dequeued.
+func (q *PersistentQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.len
+}
+
+// Close flushes and closes the queue's write segment, preventing further enqueues, and unblocks
+// any goroutine parked in BlockingDequeue. Items already on disk remain readable: BlockingDequeue
+// and TryDequeue keep draining them and only start returning ErrQueueClosed once the queue is
+// empty, at which point the read segment is closed too.
+func (q *PersistentQueue[T]) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	q.closed = true
+	q.cond.Broadcast()
+
+	if err := q.writeFile.Sync(); err != nil {
+		return fmt.Errorf("fifo: sync segment %d: %w", q.writeSegID, err)
+	}
+	if err := q.writeFile.Close(); err != nil {
+		return fmt.Errorf("fifo: close segment %d: %w", q.writeSegID, err)
+	}
+
+	return nil
+}
+
+// writeRecord appends a length-prefixed, CRC32-checksummed record to f.
+func writeRecord(f *os.File, payload []byte) error {
+	var header [recordHeaderSize]byte
+	binary.BigEndian.PutUint32(header[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(payload))
+
+	if _, err := f.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := f.Write(payload)
+	return err
+}
+
+// readRecord reads the record starting at offset in f, returning its payload and the offset of
+// the next record. It returns io.EOF if offset is exactly at the end of the file (a clean
+// boundary), or a non-EOF error if a record is present but truncated or fails its CRC check (a
+// torn write).
+func readRecord(f *os.File, offset int64) (payload []byte, next int64, err error) {
+	header := make([]byte, recordHeaderSize)
+	if n, err := f.ReadAt(header, offset); err != nil {
+		if errors.Is(err, io.EOF) && n == 0 {
+			// A clean boundary: offset is exactly at the end of the file, nothing left to read.
+			return nil, 0, io.EOF
+		}
+		// A short header read past a non-empty offset is a torn write, not a clean EOF.
+		return nil, 0, fmt.Errorf("fifo: truncated record header at offset %d: %v", offset, err)
+	}
+
+	length := binary.BigEndian.Uint32(header[0:4])
+	wantCRC := binary.BigEndian.Uint32(header[4:8])
+
+	payload = make([]byte, length)
+	if _, err := f.ReadAt(payload, offset+recordHeaderSize); err != nil {
+		return nil, 0, fmt.Errorf("fifo: truncated record at offset %d: %v", offset, err)
+	}
+	if crc32.ChecksumIEEE(payload) != wantCRC {
+		return nil, 0, fmt.Errorf("fifo: crc mismatch at offset %d", offset)
+	}
+
+	return payload, offset + recordHeaderSize + int64(length), nil
+}
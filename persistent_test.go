@@ -0,0 +1,239 @@
+package fifo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPersistentQueue_EnqueueDequeue(t *testing.T) {
+	dir := t.TempDir()
+	q, err := OpenPersistentQueue[string](dir, GobCodec[string]{}, 0, SyncAlways())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer q.Close()
+
+	for _, v := range []string{"a", "b", "c"} {
+		if err := q.TryEnqueue(v); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if q.Len() != 3 {
+		t.Fatalf("expected len 3, got: %d", q.Len())
+	}
+
+	for _, want := range []string{"a", "b", "c"} {
+		got, err := q.TryDequeue()
+		if err != nil || got != want {
+			t.Fatalf("expected %q, got: %q, err: %v", want, got, err)
+		}
+	}
+
+	if _, err := q.TryDequeue(); err != ErrQueueEmpty {
+		t.Fatalf("expected ErrQueueEmpty, got: %v", err)
+	}
+}
+
+func TestPersistentQueue_SurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	q, err := OpenPersistentQueue[int](dir, GobCodec[int]{}, 0, SyncAlways())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 1; i <= 5; i++ {
+		q.TryEnqueue(i)
+	}
+	if _, err := q.TryDequeue(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	q.Close()
+
+	reopened, err := OpenPersistentQueue[int](dir, GobCodec[int]{}, 0, SyncAlways())
+	if err != nil {
+		t.Fatalf("unexpected error reopening: %v", err)
+	}
+	defer reopened.Close()
+
+	if reopened.Len() != 4 {
+		t.Fatalf("expected len 4, got: %d", reopened.Len())
+	}
+	for _, want := range []int{2, 3, 4, 5} {
+		got, err := reopened.TryDequeue()
+		if err != nil || got != want {
+			t.Fatalf("expected %d, got: %d, err: %v", want, got, err)
+		}
+	}
+}
+
+func TestPersistentQueue_RollsSegmentsAndDeletesConsumed(t *testing.T) {
+	dir := t.TempDir()
+
+	// Each record (a one-letter string) is small; a segment this size holds only a few of them,
+	// forcing frequent rolls.
+	q, err := OpenPersistentQueue[string](dir, GobCodec[string]{}, recordHeaderSize*3+32, SyncAlways())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer q.Close()
+
+	const n = 40
+	for i := 0; i < n; i++ {
+		if err := q.TryEnqueue(string(rune('a' + i%26))); err != nil {
+			t.Fatalf("unexpected error enqueuing %d: %v", i, err)
+		}
+	}
+
+	segments, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(segments) < 2 {
+		t.Fatalf("expected multiple segment files, got: %d", len(segments))
+	}
+
+	for i := 0; i < n; i++ {
+		want := string(rune('a' + i%26))
+		got, err := q.TryDequeue()
+		if err != nil || got != want {
+			t.Fatalf("expected %q, got: %q, err: %v", want, got, err)
+		}
+	}
+
+	// Every segment except the active one should have been unlinked as it was drained.
+	remaining, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var segFiles int
+	for _, e := range remaining {
+		if filepath.Ext(e.Name()) == ".seg" {
+			segFiles++
+		}
+	}
+	if segFiles != 1 {
+		t.Fatalf("expected 1 remaining segment file, got: %d", segFiles)
+	}
+}
+
+func TestPersistentQueue_RecoversFromTornWrite(t *testing.T) {
+	dir := t.TempDir()
+
+	q, err := OpenPersistentQueue[string](dir, GobCodec[string]{}, 0, SyncAlways())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	q.TryEnqueue("complete")
+	q.Close()
+
+	// Simulate a crash mid-append: append a truncated, bogus trailing record.
+	segPath := filepath.Join(dir, "00000000000000000000.seg")
+	f, err := os.OpenFile(segPath, os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := f.Write([]byte{0x00, 0x00, 0x00, 0xFF, 0x01, 0x02}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f.Close()
+
+	reopened, err := OpenPersistentQueue[string](dir, GobCodec[string]{}, 0, SyncAlways())
+	if err != nil {
+		t.Fatalf("unexpected error recovering: %v", err)
+	}
+	defer reopened.Close()
+
+	got, err := reopened.TryDequeue()
+	if err != nil || got != "complete" {
+		t.Fatalf("expected %q, got: %q, err: %v", "complete", got, err)
+	}
+	if _, err := reopened.TryDequeue(); err != ErrQueueEmpty {
+		t.Fatalf("expected ErrQueueEmpty after torn write was truncated, got: %v", err)
+	}
+
+	// The now-healthy segment must still accept new appends past the truncated tail.
+	if err := reopened.TryEnqueue("after-recovery"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err = reopened.TryDequeue()
+	if err != nil || got != "after-recovery" {
+		t.Fatalf("expected %q, got: %q, err: %v", "after-recovery", got, err)
+	}
+}
+
+func TestPersistentQueue_BlockingDequeueWaitsForEnqueue(t *testing.T) {
+	dir := t.TempDir()
+	q, err := OpenPersistentQueue[int](dir, GobCodec[int]{}, 0, SyncAlways())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer q.Close()
+
+	done := make(chan int, 1)
+	go func() {
+		v, err := q.BlockingDequeue()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		done <- v
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	q.TryEnqueue(42)
+
+	select {
+	case v := <-done:
+		if v != 42 {
+			t.Fatalf("expected 42, got: %d", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("BlockingDequeue did not return after enqueue")
+	}
+}
+
+func TestPersistentQueue_CloseUnblocksBlockingDequeue(t *testing.T) {
+	dir := t.TempDir()
+	q, err := OpenPersistentQueue[int](dir, GobCodec[int]{}, 0, SyncAlways())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := q.BlockingDequeue()
+		done <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	q.Close()
+
+	select {
+	case err := <-done:
+		if err != ErrQueueClosed {
+			t.Fatalf("expected ErrQueueClosed, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("BlockingDequeue was not unblocked by Close")
+	}
+}
+
+func TestJSONCodec_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	q, err := OpenPersistentQueue[map[string]int](dir, JSONCodec[map[string]int]{}, 0, SyncAlways())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer q.Close()
+
+	in := map[string]int{"x": 1}
+	if err := q.TryEnqueue(in); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err := q.TryDequeue()
+	if err != nil || out["x"] != 1 {
+		t.Fatalf("expected %v, got: %v, err: %v", in, out, err)
+	}
+}
@@ -2,8 +2,11 @@
 package fifo
 
 import (
+	"context"
 	"errors"
+	"iter"
 	"sync"
+	"time"
 )
 
 // ErrQueueFull is returned when an attempt is made to add an element to a full queue.
@@ -18,6 +21,98 @@ var ErrCapacityNotPositive = errors.New("capacity must be positive")
 // ErrQueueClosed is returned when an attempt is made to perform an operation on a closed queue.
 var ErrQueueClosed = errors.New("queue is closed")
 
+// ErrInvalidBatchSize is returned when a batch operation is given a non-positive min, or a max
+// smaller than min.
+var ErrInvalidBatchSize = errors.New("invalid batch size")
+
+// ErrIndexOutOfRange is returned when PeekAt is given an index outside [0, Len()).
+var ErrIndexOutOfRange = errors.New("index out of range")
+
+// ErrNoCredit is returned by TryEnqueue on a dynamic-growth queue (see NewDynamic) when the queue
+// has reached its hard limit, or has reached its soft quota and no burst credit remains.
+var ErrNoCredit = errors.New("no burst credit remaining")
+
+// ErrInvalidDynamicParams is returned by NewDynamic when hardLimit is smaller than softQuota, or
+// burstCredit is negative.
+var ErrInvalidDynamicParams = errors.New("invalid dynamic queue parameters")
+
+// EventKind identifies the kind of lifecycle transition reported to an OnEvent observer (see
+// WithObserver).
+type EventKind int
+
+const (
+	// EventEnqueue fires after one or more items have been added to the queue.
+	EventEnqueue EventKind = iota
+	// EventDequeue fires after one or more items have been removed from the queue.
+	EventDequeue
+	// EventResize fires after the queue's backing ring has been resized, whether by an explicit
+	// Resize call or by automatic growth or shrinking.
+	EventResize
+	// EventClose fires once, when the queue is closed.
+	EventClose
+	// EventBlockedFull fires each time an enqueue call blocks because the queue is full.
+	EventBlockedFull
+	// EventBlockedEmpty fires each time a dequeue call blocks because the queue is empty.
+	EventBlockedEmpty
+)
+
+// Event describes a single lifecycle transition reported to an OnEvent observer (see
+// WithObserver). Len and Cap report the queue's length and capacity at the time of the event;
+// PrevCap additionally reports the capacity before the transition, for EventResize.
+type Event struct {
+	Kind    EventKind
+	Len     int
+	Cap     int
+	PrevCap int
+}
+
+// Stats is a point-in-time snapshot of a queue's lifetime activity counters, returned by
+// Queue.Stats.
+type Stats struct {
+	// Enqueued is the total number of items ever added to the queue.
+	Enqueued uint64
+	// Dequeued is the total number of items ever removed from the queue.
+	Dequeued uint64
+	// Len is the number of items currently in the queue.
+	Len int
+	// PeakLen is the highest Len the queue has reached.
+	PeakLen int
+	// BlockedFull is the number of times an enqueue call has blocked because the queue was full.
+	BlockedFull uint64
+	// BlockedEmpty is the number of times a dequeue call has blocked because the queue was empty.
+	BlockedEmpty uint64
+	// Resizes is the number of times the queue's backing ring has been resized, whether by an
+	// explicit Resize call or by automatic growth or shrinking.
+	Resizes uint64
+}
+
+// Option configures a Queue at construction time. See WithObserver.
+type Option[T any] func(*Queue[T])
+
+// WithObserver returns an Option that registers fn as the queue's event observer: fn is called
+// with an Event on every Enqueue, Dequeue, Resize, Close, BlockedFull, and BlockedEmpty
+// transition, under the queue's own mutex, so it can bridge queue activity into a caller's own
+// metrics system without this package taking a dependency on one. fn must not call back into the
+// queue it observes, since q.mu is already held when it runs.
+func WithObserver[T any](fn func(Event)) Option[T] {
+	return func(q *Queue[T]) {
+		q.onEvent = fn
+	}
+}
+
+// FullPolicy controls what happens when an enqueue is attempted against a full queue.
+type FullPolicy int
+
+const (
+	// PolicyBlock is the default policy: TryEnqueue returns ErrQueueFull and BlockingEnqueue
+	// blocks until space becomes available.
+	PolicyBlock FullPolicy = iota
+
+	// PolicyOverwrite drops the oldest item in the queue to make room for the new one, instead
+	// of returning ErrQueueFull or blocking.
+	PolicyOverwrite
+)
+
 // Queue is a thread-safe FIFO queue with resizable capacity.
 type Queue[T any] struct {
 	mu     sync.Mutex
@@ -28,10 +123,37 @@ type Queue[T any] struct {
 	len    int
 	cap    int
 	closed bool
+	policy FullPolicy
+	drops  uint64
+
+	outOnce sync.Once
+	outCh   chan T
+	inOnce  sync.Once
+	inCh    chan T
+
+	dynamic     bool
+	softQuota   int
+	hardLimit   int
+	burstCredit int
+	credit      float64
+
+	unbounded  bool
+	initialCap int
+	maxCap     int
+	shrink     bool
+
+	onEvent      func(Event)
+	enqueued     uint64
+	dequeued     uint64
+	peakLen      int
+	blockedFull  uint64
+	blockedEmpty uint64
+	resizes      uint64
 }
 
-// New creates a new Queue with the given initial capacity, or panics if the capacity is not positive.
-func New[T any](initialCapacity int) *Queue[T] {
+// New creates a new Queue with the given initial capacity, or panics if the capacity is not
+// positive. Options (see WithObserver) configure optional behavior.
+func New[T any](initialCapacity int, opts ...Option[T]) *Queue[T] {
 	if initialCapacity <= 0 {
 		panic(ErrCapacityNotPositive)
 	}
@@ -40,9 +162,116 @@ func New[T any](initialCapacity int) *Queue[T] {
 		cap:   initialCapacity,
 	}
 	q.cond = sync.NewCond(&q.mu)
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// NewWithPolicy creates a new Queue with the given initial capacity and full policy, or panics if
+// the capacity is not positive.
+func NewWithPolicy[T any](initialCapacity int, policy FullPolicy, opts ...Option[T]) *Queue[T] {
+	q := New[T](initialCapacity, opts...)
+	q.policy = policy
+	return q
+}
+
+// NewDynamic creates a new Queue that replaces the hard-capped ring with a soft-quota,
+// burst-credit growth scheme, as used by Tendermint's subscription queue: the queue runs a
+// credit counter that grows while Len is below softQuota (capped at burstCredit) and is spent at
+// a rate of 1 per enqueue once Len reaches softQuota, so a bursty-but-well-behaved producer isn't
+// throttled while a chronically full queue is. TryEnqueue succeeds whenever Len is below
+// hardLimit and either Len is below softQuota or credit remains, growing the underlying ring
+// geometrically (doubling, capped at hardLimit) as needed; once credit runs out or Len reaches
+// hardLimit, TryEnqueue returns ErrNoCredit and BlockingEnqueue blocks. It panics if softQuota is not positive, if
+// hardLimit is smaller than softQuota, or if burstCredit is negative.
+func NewDynamic[T any](softQuota, hardLimit, burstCredit int, opts ...Option[T]) *Queue[T] {
+	if softQuota <= 0 {
+		panic(ErrCapacityNotPositive)
+	}
+	if hardLimit < softQuota || burstCredit < 0 {
+		panic(ErrInvalidDynamicParams)
+	}
+
+	q := &Queue[T]{
+		items:       make([]T, softQuota),
+		cap:         softQuota,
+		dynamic:     true,
+		softQuota:   softQuota,
+		hardLimit:   hardLimit,
+		burstCredit: burstCredit,
+	}
+	q.cond = sync.NewCond(&q.mu)
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// Credit reports the current burst credit of a dynamic-growth queue created with NewDynamic. It
+// is always 0 for queues created any other way.
+func (q *Queue[T]) Credit() float64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.credit
+}
+
+// SoftQuota reports the soft quota of a dynamic-growth queue created with NewDynamic. It is
+// always 0 for queues created any other way.
+func (q *Queue[T]) SoftQuota() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.softQuota
+}
+
+// NewUnbounded creates a new Queue whose backing ring grows automatically instead of rejecting
+// enqueues: TryEnqueue and BlockingEnqueue double the ring in place, reusing the same wrap-aware
+// copy Resize uses, whenever it fills up, the way ring-go doubles its backing array when head and
+// tail collide. Growth is unbounded by default; call MaxCap to put a ceiling on it. Automatic
+// shrinking back down as the queue empties is off by default; enable it with SetShrinkOnDequeue.
+// It panics if initialCapacity is not positive.
+func NewUnbounded[T any](initialCapacity int, opts ...Option[T]) *Queue[T] {
+	q := New[T](initialCapacity, opts...)
+	q.unbounded = true
+	q.initialCap = initialCapacity
 	return q
 }
 
+// MaxCap sets a ceiling on automatic growth for an unbounded queue (see NewUnbounded). Once the
+// ring has grown to reach maxCap, TryEnqueue returns ErrQueueFull instead of growing further, and
+// BlockingEnqueue blocks as it would on an ordinary full queue. A maxCap of 0, the default, means
+// growth is unbounded.
+func (q *Queue[T]) MaxCap(maxCap int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.maxCap = maxCap
+}
+
+// SetShrinkOnDequeue enables or disables automatic shrinking for an unbounded queue (see
+// NewUnbounded). When enabled, a Dequeue that leaves the ring less than a quarter full halves its
+// capacity, reusing Resize's copy path, down to a floor of initialCapacity. Disabled by default,
+// since it trades a Dequeue-time copy for memory held by a long-idle queue.
+func (q *Queue[T]) SetShrinkOnDequeue(enabled bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.shrink = enabled
+}
+
+// SetFullPolicy changes the queue's full policy.
+func (q *Queue[T]) SetFullPolicy(policy FullPolicy) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.policy = policy
+}
+
+// Drops returns the number of items that have been dropped from the front of the queue to make
+// room for newly enqueued items under PolicyOverwrite.
+func (q *Queue[T]) Drops() uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.drops
+}
+
 // Len returns the number of items in the queue.
 func (q *Queue[T]) Len() int {
 	q.mu.Lock()
@@ -57,7 +286,152 @@ func (q *Queue[T]) Cap() int {
 	return q.cap
 }
 
-// TryEnqueue attempts to add an item to the end of the queue. If the queue is full, ErrQueueFull is returned immediately.
+// Stats returns a snapshot of the queue's lifetime activity counters: total items enqueued and
+// dequeued, current and peak length, how many times an enqueue or dequeue call has blocked, and
+// how many times the ring has been resized. It gives an operator the same kind of insight into a
+// bounded in-memory queue that Prometheus-wrapped workerpools and pubsub queues provide, without
+// pulling in a metrics dependency.
+func (q *Queue[T]) Stats() Stats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return Stats{
+		Enqueued:     q.enqueued,
+		Dequeued:     q.dequeued,
+		Len:          q.len,
+		PeakLen:      q.peakLen,
+		BlockedFull:  q.blockedFull,
+		BlockedEmpty: q.blockedEmpty,
+		Resizes:      q.resizes,
+	}
+}
+
+// observeLocked reports ev to the queue's observer, if one was configured with WithObserver. The
+// caller must hold q.mu.
+func (q *Queue[T]) observeLocked(ev Event) {
+	if q.onEvent != nil {
+		q.onEvent(ev)
+	}
+}
+
+// recordEnqueueLocked updates enqueue counters and peak length after n items have been added to
+// the ring, and reports an EventEnqueue to the observer, if any. The caller must hold q.mu.
+func (q *Queue[T]) recordEnqueueLocked(n int) {
+	q.enqueued += uint64(n)
+	if q.len > q.peakLen {
+		q.peakLen = q.len
+	}
+	q.observeLocked(Event{Kind: EventEnqueue, Len: q.len, Cap: q.cap})
+}
+
+// recordDequeueLocked updates dequeue counters after n items have been removed from the ring, and
+// reports an EventDequeue to the observer, if any. The caller must hold q.mu.
+func (q *Queue[T]) recordDequeueLocked(n int) {
+	q.dequeued += uint64(n)
+	q.observeLocked(Event{Kind: EventDequeue, Len: q.len, Cap: q.cap})
+}
+
+// recordBlockedFullLocked counts one instance of an enqueue call blocking because the queue is
+// full, and reports an EventBlockedFull to the observer, if any. The caller must hold q.mu.
+func (q *Queue[T]) recordBlockedFullLocked() {
+	q.blockedFull++
+	q.observeLocked(Event{Kind: EventBlockedFull, Len: q.len, Cap: q.cap})
+}
+
+// recordBlockedEmptyLocked counts one instance of a dequeue call blocking because the queue is
+// empty, and reports an EventBlockedEmpty to the observer, if any. The caller must hold q.mu.
+func (q *Queue[T]) recordBlockedEmptyLocked() {
+	q.blockedEmpty++
+	q.observeLocked(Event{Kind: EventBlockedEmpty, Len: q.len, Cap: q.cap})
+}
+
+// Peek returns the item at the front of the queue without removing it. If the queue is empty,
+// ErrQueueEmpty is returned (or ErrQueueClosed if the queue was also closed).
+func (q *Queue[T]) Peek() (T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var zero T
+	if q.len == 0 {
+		if q.closed {
+			return zero, ErrQueueClosed
+		}
+		return zero, ErrQueueEmpty
+	}
+
+	return q.items[q.head], nil
+}
+
+// PeekAt returns the i-th item from the front of the queue (0 is the next item Dequeue would
+// return) without removing it. ErrIndexOutOfRange is returned if i is not in [0, Len()).
+func (q *Queue[T]) PeekAt(i int) (T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var zero T
+	if i < 0 || i >= q.len {
+		return zero, ErrIndexOutOfRange
+	}
+
+	return q.items[(q.head+i)%cap(q.items)], nil
+}
+
+// PeekN returns up to max items from the front of the queue, in order from front to back, without
+// removing them. It returns fewer than max items if that is all the queue holds, and an empty
+// slice (not an error) if the queue is empty. It is not named Peek because Go does not allow a
+// second Peek method with a different signature; use a single copy across the ring's wrap
+// boundary rather than PeekAt in a loop when you need more than one item, e.g. for checkpointing
+// or idempotent retry logic.
+func (q *Queue[T]) PeekN(max int) ([]T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	n := max
+	if n > q.len {
+		n = q.len
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+
+	values := make([]T, n)
+	c := copy(values, q.items[q.head:])
+	if c < n {
+		copy(values[c:], q.items)
+	}
+
+	return values, nil
+}
+
+// Values returns a snapshot of the items currently in the queue, in order from front to back.
+func (q *Queue[T]) Values() []T {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	values := make([]T, q.len)
+	c := copy(values, q.items[q.head:])
+	if c < q.len {
+		copy(values[c:], q.items)
+	}
+
+	return values
+}
+
+// All returns an iterator over a snapshot of the queue's items, in order from front to back,
+// paired with their index. The snapshot is taken when All is called, so it is unaffected by
+// concurrent enqueues/dequeues made while the iteration is in progress.
+func (q *Queue[T]) All() iter.Seq2[int, T] {
+	values := q.Values()
+	return func(yield func(int, T) bool) {
+		for i, v := range values {
+			if !yield(i, v) {
+				return
+			}
+		}
+	}
+}
+
+// TryEnqueue attempts to add an item to the end of the queue. If the queue is full, ErrQueueFull is returned immediately. On a dynamic-growth queue (see NewDynamic), ErrNoCredit is returned instead once the hard limit is reached, or once the soft quota is reached and no burst credit remains.
 func (q *Queue[T]) TryEnqueue(item T) error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
@@ -66,24 +440,52 @@ func (q *Queue[T]) TryEnqueue(item T) error {
 		return ErrQueueClosed
 	}
 
-	if q.len >= q.cap {
-		return ErrQueueFull
+	if q.dynamic {
+		if !q.creditAdmitsLocked() {
+			return ErrNoCredit
+		}
+		q.admitLocked()
+	} else if q.unbounded {
+		if q.len >= q.cap {
+			if q.atMaxCapLocked() {
+				return ErrQueueFull
+			}
+			q.growUnboundedLocked()
+		}
+	} else if q.len >= q.cap {
+		if q.policy != PolicyOverwrite {
+			return ErrQueueFull
+		}
+		q.evictLocked()
 	}
 
 	q.items[q.tail] = item
 	q.tail = (q.tail + 1) % cap(q.items)
 	q.len++
+	q.recordEnqueueLocked(1)
 	q.cond.Broadcast()
 
 	return nil
 }
 
-// Enqueue adds an item to the end of the queue. If the queue is full, the calling goroutine is blocked until space becomes available.
-func (q *Queue[T]) Enqueue(item T) error {
+// BlockingEnqueue adds an item to the end of the queue. If the queue is full, the calling goroutine is blocked until space becomes available, unless the queue's full policy is PolicyOverwrite, in which case the oldest item is dropped to make room. On a dynamic-growth queue (see NewDynamic), the goroutine blocks instead until Len is below the hard limit and either below the soft quota or backed by burst credit. On an unbounded queue (see NewUnbounded), the ring grows instead of blocking, unless MaxCap has been reached.
+func (q *Queue[T]) BlockingEnqueue(item T) error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	for q.len >= q.cap && !q.closed {
+	for !q.closed {
+		if q.dynamic {
+			if q.creditAdmitsLocked() {
+				break
+			}
+		} else if q.unbounded {
+			if q.len < q.cap || !q.atMaxCapLocked() {
+				break
+			}
+		} else if q.len < q.cap || q.policy == PolicyOverwrite {
+			break
+		}
+		q.recordBlockedFullLocked()
 		q.cond.Wait()
 	}
 
@@ -91,14 +493,201 @@ func (q *Queue[T]) Enqueue(item T) error {
 		return ErrQueueClosed
 	}
 
+	if q.dynamic {
+		q.admitLocked()
+	} else if q.unbounded {
+		if q.len >= q.cap {
+			q.growUnboundedLocked()
+		}
+	} else if q.len >= q.cap {
+		q.evictLocked()
+	}
+
 	q.items[q.tail] = item
 	q.tail = (q.tail + 1) % cap(q.items)
 	q.len++
+	q.recordEnqueueLocked(1)
 	q.cond.Broadcast()
 
 	return nil
 }
 
+// Enqueue adds an item to the end of the queue, blocking until space becomes available.
+//
+// Deprecated: this method was renamed to BlockingEnqueue when EnqueueContext and PollEnqueue were
+// added, to make the blocking behavior explicit; Enqueue is kept as an alias for existing callers.
+func (q *Queue[T]) Enqueue(item T) error {
+	return q.BlockingEnqueue(item)
+}
+
+// atMaxCapLocked reports whether an unbounded queue has reached its MaxCap growth ceiling, if
+// any. The caller must hold q.mu.
+func (q *Queue[T]) atMaxCapLocked() bool {
+	return q.maxCap > 0 && q.cap >= q.maxCap
+}
+
+// growUnboundedLocked doubles an unbounded queue's capacity, capped at maxCap if one is set,
+// reusing resizeLocked's wrap-aware copy. The caller must hold q.mu.
+func (q *Queue[T]) growUnboundedLocked() {
+	newCap := q.cap * 2
+	if q.maxCap > 0 && newCap > q.maxCap {
+		newCap = q.maxCap
+	}
+	q.resizeLocked(newCap)
+}
+
+// maybeShrinkLocked halves an unbounded queue's capacity, reusing resizeLocked's copy path, if
+// shrinking is enabled (see SetShrinkOnDequeue) and the queue has just dropped below a quarter
+// full. The caller must hold q.mu.
+func (q *Queue[T]) maybeShrinkLocked() {
+	if !q.unbounded || !q.shrink || q.cap <= q.initialCap || q.len >= q.cap/4 {
+		return
+	}
+
+	newCap := q.cap / 2
+	if newCap < q.initialCap {
+		newCap = q.initialCap
+	}
+	q.resizeLocked(newCap)
+}
+
+// creditAdmitsLocked reports whether a dynamic-growth queue currently has room for one more item,
+// without mutating the credit counter or growing the ring. The caller must hold q.mu.
+func (q *Queue[T]) creditAdmitsLocked() bool {
+	if q.len >= q.hardLimit {
+		return false
+	}
+	return q.len < q.softQuota || q.credit >= 1
+}
+
+// admitLocked updates a dynamic-growth queue's credit counter to reflect admitting one item, and
+// doubles the ring's capacity (capped at hardLimit) if it is full. The caller must hold q.mu and
+// must have already verified creditAdmitsLocked.
+func (q *Queue[T]) admitLocked() {
+	if q.len < q.softQuota {
+		q.credit += float64(q.softQuota-q.len) / float64(q.softQuota)
+		if q.credit > float64(q.burstCredit) {
+			q.credit = float64(q.burstCredit)
+		}
+	} else {
+		q.credit--
+	}
+
+	if q.len >= q.cap {
+		newCap := q.cap * 2
+		if newCap > q.hardLimit {
+			newCap = q.hardLimit
+		}
+		q.resizeLocked(newCap)
+	}
+}
+
+// evictLocked drops the oldest item in the queue to make room for a new one, under
+// PolicyOverwrite. The caller must hold q.mu and must have already verified the queue is full.
+func (q *Queue[T]) evictLocked() {
+	var zero T
+	q.items[q.head] = zero // Clear the reference to allow garbage collection
+	q.head = (q.head + 1) % cap(q.items)
+	q.len--
+	q.drops++
+}
+
+// admitsOneLocked reports whether the queue currently has room to accept one more item, given its
+// mode: a dynamic-growth queue (see NewDynamic) has room while creditAdmitsLocked does; an
+// unbounded queue (see NewUnbounded) has room unless it has reached MaxCap; any other queue has
+// room below its capacity, or always under PolicyOverwrite. The caller must hold q.mu.
+func (q *Queue[T]) admitsOneLocked() bool {
+	if q.dynamic {
+		return q.creditAdmitsLocked()
+	}
+	if q.unbounded {
+		return q.len < q.cap || !q.atMaxCapLocked()
+	}
+	return q.len < q.cap || q.policy == PolicyOverwrite
+}
+
+// prepareEnqueueLocked makes room for one more item according to the queue's mode, spending burst
+// credit and growing the ring (dynamic), growing the ring (unbounded), or evicting the oldest item
+// (PolicyOverwrite) as needed. The caller must hold q.mu and must have already verified
+// admitsOneLocked.
+func (q *Queue[T]) prepareEnqueueLocked() {
+	if q.dynamic {
+		q.admitLocked()
+		return
+	}
+	if q.unbounded {
+		if q.len >= q.cap {
+			q.growUnboundedLocked()
+		}
+		return
+	}
+	if q.len >= q.cap {
+		q.evictLocked()
+	}
+}
+
+// enqueueOneLocked writes a single item into the ring and records it, assuming the caller has
+// already verified admitsOneLocked and called prepareEnqueueLocked. The caller must hold q.mu.
+func (q *Queue[T]) enqueueOneLocked(item T) {
+	q.items[q.tail] = item
+	q.tail = (q.tail + 1) % cap(q.items)
+	q.len++
+}
+
+// EnqueueContext adds an item to the end of the queue, blocking until space becomes available,
+// the queue is closed, or ctx is done. If ctx is done before space becomes available, its error
+// (context.Canceled or context.DeadlineExceeded) is returned. On a dynamic-growth queue (see
+// NewDynamic), it blocks instead until Len is below the hard limit and either below the soft quota
+// or backed by burst credit. On an unbounded
+// queue (see NewUnbounded), the ring grows instead of blocking, unless MaxCap has been reached.
+func (q *Queue[T]) EnqueueContext(ctx context.Context, item T) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	stop := q.watchContext(ctx)
+	defer stop()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.closed && !q.admitsOneLocked() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		q.recordBlockedFullLocked()
+		q.cond.Wait()
+	}
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	q.prepareEnqueueLocked()
+	q.enqueueOneLocked(item)
+	q.recordEnqueueLocked(1)
+	q.cond.Broadcast()
+
+	return nil
+}
+
+// PollEnqueue adds an item to the end of the queue, blocking until space becomes available,
+// the queue is closed, or timeout elapses. If timeout elapses first, context.DeadlineExceeded
+// is returned.
+func (q *Queue[T]) PollEnqueue(item T, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return q.EnqueueContext(ctx, item)
+}
+
+// EnqueueCtx is an alias for EnqueueContext, named to pair with DequeueCtx.
+func (q *Queue[T]) EnqueueCtx(ctx context.Context, item T) error {
+	return q.EnqueueContext(ctx, item)
+}
+
 // TryDequeue attempts to remove and returns the item at the front of the queue. If the queue is empty, ErrQueueEmpty is returned immediately.
 func (q *Queue[T]) TryDequeue() (T, error) {
 	q.mu.Lock()
@@ -116,13 +705,15 @@ func (q *Queue[T]) TryDequeue() (T, error) {
 	q.items[q.head] = zero // Clear the reference to allow garbage collection
 	q.head = (q.head + 1) % cap(q.items)
 	q.len--
+	q.recordDequeueLocked(1)
+	q.maybeShrinkLocked()
 	q.cond.Broadcast()
 
 	return item, nil
 }
 
-// Dequeue removes and returns the item at the front of the queue. If the queue is empty, the calling goroutine is blocked until an item becomes available.
-func (q *Queue[T]) Dequeue() (T, error) {
+// BlockingDequeue removes and returns the item at the front of the queue. If the queue is empty, the calling goroutine is blocked until an item becomes available.
+func (q *Queue[T]) BlockingDequeue() (T, error) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
@@ -131,23 +722,307 @@ func (q *Queue[T]) Dequeue() (T, error) {
 		if q.closed {
 			return zero, ErrQueueClosed
 		}
+		q.recordBlockedEmptyLocked()
+		q.cond.Wait()
+	}
+
+	item := q.items[q.head]
+	q.items[q.head] = zero
+	q.head = (q.head + 1) % cap(q.items)
+	q.len--
+	q.recordDequeueLocked(1)
+	q.maybeShrinkLocked()
+	q.cond.Broadcast()
+
+	return item, nil
+}
+
+// Dequeue removes and returns the item at the front of the queue, blocking until an item becomes
+// available.
+//
+// Deprecated: this method was renamed to BlockingDequeue when DequeueContext and PollDequeue were
+// added, to make the blocking behavior explicit; Dequeue is kept as an alias for existing callers.
+func (q *Queue[T]) Dequeue() (T, error) {
+	return q.BlockingDequeue()
+}
+
+// DequeueContext removes and returns the item at the front of the queue, blocking until an item
+// becomes available, the queue is closed, or ctx is done. If ctx is done before an item becomes
+// available, its error (context.Canceled or context.DeadlineExceeded) is returned.
+func (q *Queue[T]) DequeueContext(ctx context.Context) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	stop := q.watchContext(ctx)
+	defer stop()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.len == 0 {
+		if q.closed {
+			return zero, ErrQueueClosed
+		}
+		if err := ctx.Err(); err != nil {
+			return zero, err
+		}
+		q.recordBlockedEmptyLocked()
 		q.cond.Wait()
 	}
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
 
 	item := q.items[q.head]
 	q.items[q.head] = zero
 	q.head = (q.head + 1) % cap(q.items)
 	q.len--
+	q.recordDequeueLocked(1)
+	q.maybeShrinkLocked()
 	q.cond.Broadcast()
 
 	return item, nil
 }
 
-// Resize changes the capacity of the queue. It returns an error if the new capacity is not positive, or if the queue is closed.
+// PollDequeue removes and returns the item at the front of the queue, blocking until an item
+// becomes available, the queue is closed, or timeout elapses. If timeout elapses first,
+// context.DeadlineExceeded is returned.
+func (q *Queue[T]) PollDequeue(timeout time.Duration) (T, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return q.DequeueContext(ctx)
+}
+
+// DequeueCtx is an alias for DequeueContext, named to pair with EnqueueCtx.
+func (q *Queue[T]) DequeueCtx(ctx context.Context) (T, error) {
+	return q.DequeueContext(ctx)
+}
+
+// watchContext starts a goroutine that broadcasts on the queue's condition variable when ctx is
+// done, so that a goroutine blocked in cond.Wait can wake up and re-check ctx.Err(). Because this
+// uses Broadcast rather than Signal, a cancelled waiter can never consume a wakeup meant for an
+// item or slot it won't take: every other waiter wakes up alongside it and re-evaluates its own
+// condition, so no signal is ever lost to a waiter that is about to give up. The returned stop
+// function must be called once the caller is done waiting, to let the goroutine exit.
+func (q *Queue[T]) watchContext(ctx context.Context) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			q.mu.Lock()
+			q.cond.Broadcast()
+			q.mu.Unlock()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
+
+// enqueueAsManyLocked admits as many of items as currently fit, in order, growing the ring or
+// spending burst credit (dynamic), growing the ring (unbounded), or evicting the oldest item
+// (PolicyOverwrite) one item at a time, exactly as TryEnqueue would. It stops as soon as
+// admitsOneLocked reports no room, and returns the number of items actually enqueued. The caller
+// must hold q.mu.
+func (q *Queue[T]) enqueueAsManyLocked(items []T) int {
+	n := 0
+	for n < len(items) && q.admitsOneLocked() {
+		q.prepareEnqueueLocked()
+		q.enqueueOneLocked(items[n])
+		n++
+	}
+	if n > 0 {
+		q.recordEnqueueLocked(n)
+	}
+	return n
+}
+
+// EnqueueBatch blocks until the queue has room for at least one item, then enqueues as many of
+// items as currently fit in a single locked operation, returning the count actually enqueued. If
+// the returned count is less than len(items), the caller should re-call EnqueueBatch with the
+// remainder. If the queue is closed before any room becomes available, ErrQueueClosed is returned.
+// On a dynamic-growth queue (see NewDynamic), room is limited by the hard limit and burst credit
+// rather than by capacity; on an unbounded queue (see NewUnbounded), the ring grows as needed; under
+// PolicyOverwrite, the oldest items are dropped to make room instead of blocking. See
+// TryEnqueueMany for a non-blocking equivalent.
+func (q *Queue[T]) EnqueueBatch(items []T) (int, error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.closed && !q.admitsOneLocked() {
+		q.recordBlockedFullLocked()
+		q.cond.Wait()
+	}
+
+	if q.closed {
+		return 0, ErrQueueClosed
+	}
+
+	n := q.enqueueAsManyLocked(items)
+	q.cond.Broadcast()
+
+	return n, nil
+}
+
+// TryEnqueueMany attempts to add items to the end of the queue, stopping as soon as the queue is
+// full. It returns the number of items actually enqueued, which may be less than len(items) if
+// the queue does not have enough remaining capacity. If the queue is closed, (0, ErrQueueClosed)
+// is returned. If the queue is already full, (0, ErrQueueFull) is returned (or (0, ErrNoCredit) on
+// a dynamic-growth queue that is at its hard limit or has exhausted its burst credit; see
+// NewDynamic). On an unbounded
+// queue (see NewUnbounded), the ring grows as needed; under PolicyOverwrite, the oldest items are
+// dropped to make room instead of failing.
+func (q *Queue[T]) TryEnqueueMany(items []T) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return 0, ErrQueueClosed
+	}
+
+	if !q.admitsOneLocked() {
+		if q.dynamic {
+			return 0, ErrNoCredit
+		}
+		return 0, ErrQueueFull
+	}
+
+	n := q.enqueueAsManyLocked(items)
+	q.cond.Broadcast()
+
+	return n, nil
+}
+
+// TryDequeueMany attempts to remove and return up to max items from the front of the queue,
+// without blocking. It returns fewer than max items if that is all the queue holds. If the queue
+// is empty, ErrQueueEmpty is returned (or ErrQueueClosed if the queue was also closed).
+func (q *Queue[T]) TryDequeueMany(max int) ([]T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.len == 0 {
+		if q.closed {
+			return nil, ErrQueueClosed
+		}
+		return nil, ErrQueueEmpty
+	}
+
+	n := max
+	if n > q.len {
+		n = q.len
+	}
+
+	items := q.dequeueLocked(n)
+	q.recordDequeueLocked(n)
+	q.maybeShrinkLocked()
+	q.cond.Broadcast()
+
+	return items, nil
+}
+
+// DequeueBatch blocks until at least one item is available, then removes and returns up to max
+// items from the front of the queue. It is BlockingDequeueUpTo with min fixed to 1.
+func (q *Queue[T]) DequeueBatch(max int) ([]T, error) {
+	return q.BlockingDequeueUpTo(1, max, 0)
+}
+
+// TryDequeueBatch attempts to remove and return up to max items from the front of the queue
+// without blocking. It is an alias for TryDequeueMany, named to pair with DequeueBatch and
+// EnqueueBatch.
+func (q *Queue[T]) TryDequeueBatch(max int) ([]T, error) {
+	return q.TryDequeueMany(max)
+}
+
+// BlockingDequeueUpTo removes and returns between min and max items from the front of the queue.
+// It blocks until at least min items are available, the queue is closed, or timeout elapses (a
+// non-positive timeout means wait indefinitely). If timeout elapses before min items are
+// available, context.DeadlineExceeded is returned. Once the queue is closed, it returns whatever
+// items remain, which may be fewer than min.
+func (q *Queue[T]) BlockingDequeueUpTo(min, max int, timeout time.Duration) ([]T, error) {
+	if min <= 0 || max < min {
+		return nil, ErrInvalidBatchSize
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	stop := q.watchContext(ctx)
+	defer stop()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.len < min && !q.closed {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		q.recordBlockedEmptyLocked()
+		q.cond.Wait()
+	}
+
+	if q.len == 0 && q.closed {
+		return nil, ErrQueueClosed
+	}
+	if q.len < min {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	n := max
+	if n > q.len {
+		n = q.len
+	}
+
+	items := q.dequeueLocked(n)
+	q.recordDequeueLocked(n)
+	q.maybeShrinkLocked()
+	q.cond.Broadcast()
+
+	return items, nil
+}
+
+// dequeueLocked removes and returns the first n items from the ring buffer, wrapping around the
+// end of the backing array as needed. The caller must hold q.mu and must have already verified
+// that n does not exceed q.len.
+func (q *Queue[T]) dequeueLocked(n int) []T {
+	items := make([]T, n)
+	var zero T
+
+	c := copy(items, q.items[q.head:])
+	if c < n {
+		copy(items[c:], q.items)
+	}
+
+	for i := 0; i < n; i++ {
+		q.items[(q.head+i)%cap(q.items)] = zero // Clear references to allow garbage collection
+	}
+
+	q.head = (q.head + n) % cap(q.items)
+	q.len -= n
+
+	return items
+}
+
+// Resize changes the capacity of the queue. It returns an error if the new capacity is not positive, or if the queue is closed. On a dynamic-growth queue (see NewDynamic), the credit counter is untouched by a Resize.
 func (q *Queue[T]) Resize(newCap int) error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
+	return q.resizeLocked(newCap)
+}
+
+// resizeLocked implements Resize; the caller must hold q.mu.
+func (q *Queue[T]) resizeLocked(newCap int) error {
 	if newCap == q.cap {
 		return nil
 	}
@@ -158,6 +1033,8 @@ func (q *Queue[T]) Resize(newCap int) error {
 		return ErrQueueClosed
 	}
 
+	prevCap := q.cap
+
 	// Ensure no data loss
 	ns := newCap
 	if q.len > ns {
@@ -179,6 +1056,8 @@ func (q *Queue[T]) Resize(newCap int) error {
 	q.head = 0
 	q.tail = q.len % ns // Adjust the tail position based on the actual capacity of the new slice
 	q.cap = newCap
+	q.resizes++
+	q.observeLocked(Event{Kind: EventResize, Len: q.len, Cap: q.cap, PrevCap: prevCap})
 	q.cond.Broadcast() // Wake up all goroutines waiting due to full queue
 
 	return nil
@@ -194,6 +1073,56 @@ func (q *Queue[T]) Close() error {
 	}
 
 	q.closed = true
+	q.observeLocked(Event{Kind: EventClose, Len: q.len, Cap: q.cap})
 	q.cond.Broadcast()
 	return nil
 }
+
+// C returns a channel that yields the queue's items in order, for use alongside other channels in
+// a select statement. The bridging goroutine between the ring buffer and this channel is started
+// lazily on the first call to C, and closes the channel once the queue is closed and fully
+// drained. Prefer BlockingDequeue when select isn't needed: C costs one extra goroutine for the
+// lifetime of the queue and takes up to two items out of the queue ahead of whatever the consumer
+// has read: one in the channel's single buffered slot and one held by the goroutine waiting to
+// send it.
+func (q *Queue[T]) C() <-chan T {
+	q.outOnce.Do(func() {
+		q.outCh = make(chan T, 1)
+		go q.bridgeOut()
+	})
+	return q.outCh
+}
+
+// In returns a channel that feeds items into the queue, for use alongside other channels in a
+// select statement. The bridging goroutine is started lazily on the first call to In; sends made
+// after the queue is closed are silently discarded, since BlockingEnqueue would otherwise report
+// ErrQueueClosed to a send statement that has no way to observe it. Prefer BlockingEnqueue when
+// select isn't needed, for the same tradeoff as C. C and In start independent bridges, so calling
+// one does not pull items into, or out of, the other.
+func (q *Queue[T]) In() chan<- T {
+	q.inOnce.Do(func() {
+		q.inCh = make(chan T, 1)
+		go q.bridgeIn()
+	})
+	return q.inCh
+}
+
+// bridgeOut drains the queue into q.outCh until the queue is closed and empty, then closes
+// q.outCh so a range or receive on it observes the queue's end.
+func (q *Queue[T]) bridgeOut() {
+	for {
+		item, err := q.BlockingDequeue()
+		if err != nil {
+			close(q.outCh)
+			return
+		}
+		q.outCh <- item
+	}
+}
+
+// bridgeIn feeds items received on q.inCh into the queue for as long as q.inCh is open.
+func (q *Queue[T]) bridgeIn() {
+	for item := range q.inCh {
+		_ = q.BlockingEnqueue(item) // Discard ErrQueueClosed: nothing can observe a failed send.
+	}
+}
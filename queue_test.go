@@ -1,6 +1,8 @@
 package fifo
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
@@ -437,6 +439,19 @@ func TestQueue_Blocking(t *testing.T) {
 	wg.Wait()
 }
 
+func TestQueue_EnqueueDequeueAreBlockingAliases(t *testing.T) {
+	q := New[int](1)
+
+	if err := q.Enqueue(1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := q.Dequeue()
+	if err != nil || got != 1 {
+		t.Fatalf("expected 1, got: %d, err: %v", got, err)
+	}
+}
+
 func TestQueue_TryEnqueueLen(t *testing.T) {
 	q := New[int](5)
 
@@ -1050,3 +1065,1179 @@ func ExampleQueue() {
 	// Dequeued: D
 	// Dequeued: E
 }
+
+func TestQueue_EnqueueContextUnblocksOnCancel(t *testing.T) {
+	q := New[int](1)
+	q.TryEnqueue(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- q.EnqueueContext(ctx, 2)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got: %v", err)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("EnqueueContext was not unblocked by context cancellation")
+	}
+}
+
+func TestQueue_DequeueContextUnblocksOnCancel(t *testing.T) {
+	q := New[int](1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := q.DequeueContext(ctx)
+		done <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got: %v", err)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("DequeueContext was not unblocked by context cancellation")
+	}
+}
+
+func TestQueue_EnqueueContextSucceedsWhenSpaceFreed(t *testing.T) {
+	q := New[int](1)
+	q.TryEnqueue(1)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		q.TryDequeue()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := q.EnqueueContext(ctx, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestQueue_DequeueContextSucceedsWhenItemArrives(t *testing.T) {
+	q := New[int](1)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		q.TryEnqueue(1)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	item, err := q.DequeueContext(ctx)
+	if err != nil || item != 1 {
+		t.Fatalf("expected 1, got: %v, err: %v", item, err)
+	}
+}
+
+func TestQueue_EnqueueContextAlreadyDone(t *testing.T) {
+	q := New[int](1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := q.EnqueueContext(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got: %v", err)
+	}
+}
+
+func TestQueue_PollEnqueueTimesOut(t *testing.T) {
+	q := New[int](1)
+	q.TryEnqueue(1)
+
+	err := q.PollEnqueue(2, 50*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got: %v", err)
+	}
+}
+
+func TestQueue_PollDequeueTimesOut(t *testing.T) {
+	q := New[int](1)
+
+	_, err := q.PollDequeue(50 * time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got: %v", err)
+	}
+}
+
+func TestQueue_TryEnqueueManyPartial(t *testing.T) {
+	q := New[int](3)
+
+	n, err := q.TryEnqueueMany([]int{1, 2, 3, 4, 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 items enqueued, got: %d", n)
+	}
+
+	assertDequeueList(t, q, []int{1, 2, 3}, intCompare)
+}
+
+func TestQueue_TryEnqueueManyOnFullQueue(t *testing.T) {
+	q := New[int](2)
+	q.TryEnqueue(1)
+	q.TryEnqueue(2)
+
+	n, err := q.TryEnqueueMany([]int{3, 4})
+	if err != ErrQueueFull {
+		t.Fatalf("expected ErrQueueFull, got: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("expected 0 items enqueued, got: %d", n)
+	}
+}
+
+func TestQueue_TryEnqueueManyWithWraparound(t *testing.T) {
+	q := New[int](3)
+	q.TryEnqueue(1)
+	q.TryEnqueue(2)
+	q.TryEnqueue(3)
+	assertDequeueList(t, q, []int{1, 2}, intCompare)
+
+	n, err := q.TryEnqueueMany([]int{4, 5})
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 items enqueued, got: %d, err: %v", n, err)
+	}
+
+	assertDequeueList(t, q, []int{3, 4, 5}, intCompare)
+}
+
+func TestQueue_TryDequeueMany(t *testing.T) {
+	q := New[int](5)
+	q.TryEnqueueMany([]int{1, 2, 3, 4, 5})
+
+	items, err := q.TryDequeueMany(3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 3 || items[0] != 1 || items[1] != 2 || items[2] != 3 {
+		t.Fatalf("unexpected items: %v", items)
+	}
+
+	items, err = q.TryDequeueMany(10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 2 || items[0] != 4 || items[1] != 5 {
+		t.Fatalf("unexpected items: %v", items)
+	}
+}
+
+func TestQueue_TryDequeueManyOnEmptyQueue(t *testing.T) {
+	q := New[int](3)
+
+	items, err := q.TryDequeueMany(2)
+	if err != ErrQueueEmpty {
+		t.Fatalf("expected ErrQueueEmpty, got: %v", err)
+	}
+	if items != nil {
+		t.Fatalf("expected nil items, got: %v", items)
+	}
+}
+
+func TestQueue_BlockingDequeueUpToWaitsForMin(t *testing.T) {
+	q := New[int](5)
+	q.TryEnqueue(1)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		q.TryEnqueueMany([]int{2, 3})
+	}()
+
+	items, err := q.BlockingDequeueUpTo(3, 5, time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 3 || items[0] != 1 || items[1] != 2 || items[2] != 3 {
+		t.Fatalf("unexpected items: %v", items)
+	}
+}
+
+func TestQueue_BlockingDequeueUpToTimesOut(t *testing.T) {
+	q := New[int](5)
+	q.TryEnqueue(1)
+
+	_, err := q.BlockingDequeueUpTo(3, 5, 50*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got: %v", err)
+	}
+}
+
+func TestQueue_BlockingDequeueUpToInvalidSize(t *testing.T) {
+	q := New[int](5)
+
+	if _, err := q.BlockingDequeueUpTo(0, 5, time.Second); err != ErrInvalidBatchSize {
+		t.Fatalf("expected ErrInvalidBatchSize, got: %v", err)
+	}
+	if _, err := q.BlockingDequeueUpTo(3, 2, time.Second); err != ErrInvalidBatchSize {
+		t.Fatalf("expected ErrInvalidBatchSize, got: %v", err)
+	}
+}
+
+func TestQueue_Peek(t *testing.T) {
+	q := New[int](3)
+	q.TryEnqueueMany([]int{1, 2, 3})
+
+	item, err := q.Peek()
+	if err != nil || item != 1 {
+		t.Fatalf("expected 1, got: %v, err: %v", item, err)
+	}
+
+	// Peek must not remove the item.
+	if q.Len() != 3 {
+		t.Fatalf("expected len 3, got: %d", q.Len())
+	}
+}
+
+func TestQueue_PeekOnEmptyQueue(t *testing.T) {
+	q := New[int](3)
+
+	if _, err := q.Peek(); err != ErrQueueEmpty {
+		t.Fatalf("expected ErrQueueEmpty, got: %v", err)
+	}
+}
+
+func TestQueue_PeekAt(t *testing.T) {
+	q := New[int](3)
+	q.TryEnqueueMany([]int{1, 2, 3})
+	assertDequeueList(t, q, []int{1}, intCompare)
+	q.TryEnqueue(4) // Force wraparound
+
+	item, err := q.PeekAt(0)
+	if err != nil || item != 2 {
+		t.Fatalf("expected 2, got: %v, err: %v", item, err)
+	}
+
+	item, err = q.PeekAt(2)
+	if err != nil || item != 4 {
+		t.Fatalf("expected 4, got: %v, err: %v", item, err)
+	}
+
+	if _, err := q.PeekAt(3); err != ErrIndexOutOfRange {
+		t.Fatalf("expected ErrIndexOutOfRange, got: %v", err)
+	}
+	if _, err := q.PeekAt(-1); err != ErrIndexOutOfRange {
+		t.Fatalf("expected ErrIndexOutOfRange, got: %v", err)
+	}
+}
+
+func TestQueue_ValuesWithWraparound(t *testing.T) {
+	q := New[int](3)
+	q.TryEnqueueMany([]int{1, 2, 3})
+	assertDequeueList(t, q, []int{1, 2}, intCompare)
+	q.TryEnqueueMany([]int{4, 5})
+
+	values := q.Values()
+	if len(values) != 3 || values[0] != 3 || values[1] != 4 || values[2] != 5 {
+		t.Fatalf("unexpected values: %v", values)
+	}
+}
+
+func TestQueue_All(t *testing.T) {
+	q := New[int](3)
+	q.TryEnqueueMany([]int{1, 2, 3})
+
+	var got []int
+	for i, v := range q.All() {
+		if i != len(got) {
+			t.Fatalf("expected index %d, got: %d", len(got), i)
+		}
+		got = append(got, v)
+	}
+
+	if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Fatalf("unexpected values: %v", got)
+	}
+}
+
+func TestQueue_PolicyOverwriteDropsOldest(t *testing.T) {
+	q := NewWithPolicy[int](3, PolicyOverwrite)
+	q.TryEnqueue(1)
+	q.TryEnqueue(2)
+	q.TryEnqueue(3)
+
+	if err := q.TryEnqueue(4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.Drops() != 1 {
+		t.Fatalf("expected 1 drop, got: %d", q.Drops())
+	}
+
+	assertDequeueList(t, q, []int{2, 3, 4}, intCompare)
+}
+
+func TestQueue_PolicyBlockIsDefault(t *testing.T) {
+	q := New[int](1)
+	q.TryEnqueue(1)
+
+	if err := q.TryEnqueue(2); err != ErrQueueFull {
+		t.Fatalf("expected ErrQueueFull, got: %v", err)
+	}
+	if q.Drops() != 0 {
+		t.Fatalf("expected 0 drops, got: %d", q.Drops())
+	}
+}
+
+func TestQueue_SetFullPolicy(t *testing.T) {
+	q := New[int](1)
+	q.TryEnqueue(1)
+	q.SetFullPolicy(PolicyOverwrite)
+
+	if err := q.TryEnqueue(2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertDequeueList(t, q, []int{2}, intCompare)
+}
+
+func TestQueue_PolicyOverwriteUnblocksBlockingEnqueue(t *testing.T) {
+	q := NewWithPolicy[int](1, PolicyOverwrite)
+	q.TryEnqueue(1)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- q.BlockingEnqueue(2)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("BlockingEnqueue did not return immediately under PolicyOverwrite")
+	}
+
+	if q.Drops() != 1 {
+		t.Fatalf("expected 1 drop, got: %d", q.Drops())
+	}
+	assertDequeueList(t, q, []int{2}, intCompare)
+}
+
+func TestQueue_EnqueueContextCancelDoesNotStarveOtherWaiters(t *testing.T) {
+	q := New[int](1)
+	q.TryEnqueue(0) // Fill the queue so every EnqueueContext below blocks.
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+
+	cancelledDone := make(chan error, 1)
+	go func() {
+		cancelledDone <- q.EnqueueContext(cancelledCtx, -1)
+	}()
+
+	const waiters = 3
+	results := make(chan error, waiters)
+	for i := 0; i < waiters; i++ {
+		go func(n int) {
+			results <- q.EnqueueContext(context.Background(), n)
+		}(i)
+	}
+
+	// Give every goroutine above a chance to start blocking in cond.Wait before we cancel one
+	// of them and free a slot for exactly one of the rest.
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-cancelledDone:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancelled EnqueueContext was not unblocked")
+	}
+
+	// The cancellation's broadcast must not have been swallowed by a waiter that then gave up:
+	// once we dequeue, exactly one of the remaining waiters must succeed.
+	if _, err := q.TryDequeue(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case err := <-results:
+		if err != nil {
+			t.Fatalf("unexpected error from a waiting EnqueueContext: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no waiter was unblocked by the freed slot")
+	}
+
+	// Drain the other two waiters so they don't leak past the end of the test.
+	q.Close()
+	for i := 0; i < waiters-1; i++ {
+		<-results
+	}
+}
+
+func TestQueue_EnqueueContextUnblocksOnClose(t *testing.T) {
+	q := New[int](1)
+	q.TryEnqueue(1)
+
+	ctx := context.Background()
+	done := make(chan error, 1)
+	go func() {
+		done <- q.EnqueueContext(ctx, 2)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	q.Close()
+
+	select {
+	case err := <-done:
+		if err != ErrQueueClosed {
+			t.Fatalf("expected ErrQueueClosed, got: %v", err)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("EnqueueContext was not unblocked by Close")
+	}
+}
+
+func TestQueue_EnqueueCtxDequeueCtxAreContextAliases(t *testing.T) {
+	q := New[int](1)
+	ctx := context.Background()
+
+	if err := q.EnqueueCtx(ctx, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := q.DequeueCtx(ctx)
+	if err != nil || got != 1 {
+		t.Fatalf("expected 1, got: %d, err: %v", got, err)
+	}
+}
+
+func TestQueue_CReceivesEnqueuedItems(t *testing.T) {
+	q := New[int](4)
+	out := q.C()
+
+	q.TryEnqueue(1)
+	q.TryEnqueue(2)
+
+	for _, want := range []int{1, 2} {
+		select {
+		case got := <-out:
+			if got != want {
+				t.Fatalf("expected %d, got: %d", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("C did not yield the enqueued item")
+		}
+	}
+}
+
+func TestQueue_CClosesAfterQueueClosedAndDrained(t *testing.T) {
+	q := New[int](4)
+	out := q.C()
+
+	q.TryEnqueue(1)
+	q.Close()
+
+	select {
+	case got, ok := <-out:
+		if !ok || got != 1 {
+			t.Fatalf("expected (1, true), got: (%d, %v)", got, ok)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("C did not yield the item enqueued before Close")
+	}
+
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("expected C to be closed once the queue was drained")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("C was not closed after the queue drained")
+	}
+}
+
+func TestQueue_InFeedsEnqueuedItems(t *testing.T) {
+	q := New[int](4)
+	in := q.In()
+
+	in <- 7
+	in <- 8
+
+	// Give the bridging goroutine a chance to move the items into the ring buffer.
+	time.Sleep(100 * time.Millisecond)
+
+	for _, want := range []int{7, 8} {
+		got, err := q.TryDequeue()
+		if err != nil || got != want {
+			t.Fatalf("expected %d, got: %d, err: %v", want, got, err)
+		}
+	}
+}
+
+func TestQueue_SelectOverCAndIn(t *testing.T) {
+	q := New[int](4)
+	q.TryEnqueue(42)
+
+	select {
+	case got := <-q.C():
+		if got != 42 {
+			t.Fatalf("expected 42, got: %d", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("select did not receive from C")
+	}
+
+	select {
+	case q.In() <- 99:
+	case <-time.After(time.Second):
+		t.Fatal("select did not send to In")
+	}
+
+	// C() is already bridging the queue, so the item sent via In arrives back out through C
+	// rather than through a direct TryDequeue.
+	select {
+	case got := <-q.C():
+		if got != 99 {
+			t.Fatalf("expected 99, got: %d", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("select did not receive the item sent via In")
+	}
+}
+
+func TestQueue_EnqueueBatchBlocksUntilRoom(t *testing.T) {
+	q := New[int](2)
+	q.TryEnqueue(1)
+	q.TryEnqueue(2)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		q.TryDequeue()
+	}()
+
+	n, err := q.EnqueueBatch([]int{3, 4, 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 item enqueued, got: %d", n)
+	}
+
+	assertDequeueList(t, q, []int{2, 3}, intCompare)
+}
+
+func TestQueue_EnqueueBatchUnblocksOnClose(t *testing.T) {
+	q := New[int](1)
+	q.TryEnqueue(1)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := q.EnqueueBatch([]int{2, 3})
+		done <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	q.Close()
+
+	select {
+	case err := <-done:
+		if err != ErrQueueClosed {
+			t.Fatalf("expected ErrQueueClosed, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("EnqueueBatch was not unblocked by Close")
+	}
+}
+
+func TestQueue_EnqueueBatchEvictsUnderPolicyOverwrite(t *testing.T) {
+	q := NewWithPolicy[int](2, PolicyOverwrite)
+
+	n, err := q.EnqueueBatch([]int{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 4 {
+		t.Fatalf("expected all 4 items enqueued under PolicyOverwrite, got: %d", n)
+	}
+
+	assertDequeueList(t, q, []int{3, 4}, intCompare)
+}
+
+func TestQueue_TryEnqueueManyEvictsUnderPolicyOverwrite(t *testing.T) {
+	q := NewWithPolicy[int](2, PolicyOverwrite)
+
+	n, err := q.TryEnqueueMany([]int{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 4 {
+		t.Fatalf("expected all 4 items enqueued under PolicyOverwrite, got: %d", n)
+	}
+
+	assertDequeueList(t, q, []int{3, 4}, intCompare)
+}
+
+func TestQueue_DequeueBatchWaitsForAnyItem(t *testing.T) {
+	q := New[int](5)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		q.TryEnqueueMany([]int{1, 2})
+	}()
+
+	items, err := q.DequeueBatch(5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 2 || items[0] != 1 || items[1] != 2 {
+		t.Fatalf("unexpected items: %v", items)
+	}
+}
+
+func TestQueue_TryDequeueBatchMatchesTryDequeueMany(t *testing.T) {
+	q := New[int](5)
+	q.TryEnqueueMany([]int{1, 2, 3})
+
+	items, err := q.TryDequeueBatch(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 2 || items[0] != 1 || items[1] != 2 {
+		t.Fatalf("unexpected items: %v", items)
+	}
+}
+
+func TestQueue_PeekN(t *testing.T) {
+	q := New[int](3)
+	q.TryEnqueueMany([]int{1, 2, 3})
+
+	items, err := q.PeekN(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 2 || items[0] != 1 || items[1] != 2 {
+		t.Fatalf("unexpected items: %v", items)
+	}
+
+	// PeekN must not remove the items.
+	if q.Len() != 3 {
+		t.Fatalf("expected len 3, got: %d", q.Len())
+	}
+}
+
+func TestQueue_PeekNMoreThanAvailable(t *testing.T) {
+	q := New[int](5)
+	q.TryEnqueueMany([]int{1, 2})
+
+	items, err := q.PeekN(5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 2 || items[0] != 1 || items[1] != 2 {
+		t.Fatalf("unexpected items: %v", items)
+	}
+}
+
+func TestQueue_PeekNOnEmptyQueue(t *testing.T) {
+	q := New[int](3)
+
+	items, err := q.PeekN(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("expected no items, got: %v", items)
+	}
+}
+
+func TestQueue_PeekNWithWraparound(t *testing.T) {
+	q := New[int](3)
+	q.TryEnqueueMany([]int{1, 2, 3})
+	assertDequeueList(t, q, []int{1}, intCompare)
+	q.TryEnqueue(4)
+
+	items, err := q.PeekN(3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 3 || items[0] != 2 || items[1] != 3 || items[2] != 4 {
+		t.Fatalf("unexpected items: %v", items)
+	}
+}
+
+func TestNewDynamic_PanicsOnInvalidParams(t *testing.T) {
+	assertPanics := func(t *testing.T, f func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected a panic")
+			}
+		}()
+		f()
+	}
+
+	assertPanics(t, func() { NewDynamic[int](0, 10, 5) })
+	assertPanics(t, func() { NewDynamic[int](10, 5, 5) })
+	assertPanics(t, func() { NewDynamic[int](5, 10, -1) })
+}
+
+func TestQueue_DynamicBelowSoftQuotaAccruesCredit(t *testing.T) {
+	q := NewDynamic[int](4, 8, 2)
+
+	for i := 0; i < 3; i++ {
+		if err := q.TryEnqueue(i); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if q.SoftQuota() != 4 {
+		t.Fatalf("expected soft quota 4, got: %d", q.SoftQuota())
+	}
+	if c := q.Credit(); c <= 0 {
+		t.Fatalf("expected positive credit below soft quota, got: %f", c)
+	}
+}
+
+func TestQueue_DynamicSpendsCreditAtSoftQuota(t *testing.T) {
+	q := NewDynamic[int](2, 10, 3)
+
+	// Fill to the soft quota, accruing credit along the way.
+	q.TryEnqueue(1)
+	q.TryEnqueue(2)
+	credit := q.Credit()
+	if credit <= 0 {
+		t.Fatalf("expected positive credit at soft quota, got: %f", credit)
+	}
+
+	// Every enqueue past the soft quota spends one credit.
+	for credit >= 1 {
+		if err := q.TryEnqueue(3); err != nil {
+			t.Fatalf("unexpected error while credit remained: %v, credit: %f", err, credit)
+		}
+		credit--
+		if got := q.Credit(); got != credit {
+			t.Fatalf("expected credit %f, got: %f", credit, got)
+		}
+	}
+
+	if err := q.TryEnqueue(4); err != ErrNoCredit {
+		t.Fatalf("expected ErrNoCredit once credit ran out, got: %v", err)
+	}
+}
+
+func TestQueue_DynamicNeverExceedsHardLimit(t *testing.T) {
+	q := NewDynamic[int](2, 4, 1000)
+
+	// Prime a large credit balance by cycling enqueue/dequeue while staying below the soft quota.
+	for i := 0; i < 2000; i++ {
+		q.TryEnqueue(i)
+		q.TryDequeue()
+	}
+
+	var enqueued int
+	for i := 0; i < 20; i++ {
+		if err := q.TryEnqueue(i); err != nil {
+			break
+		}
+		enqueued++
+	}
+
+	if enqueued != 4 {
+		t.Fatalf("expected exactly hardLimit (4) items enqueued, got: %d", enqueued)
+	}
+	if q.Len() != 4 {
+		t.Fatalf("expected len 4, got: %d", q.Len())
+	}
+}
+
+func TestQueue_DynamicGrowsRingGeometrically(t *testing.T) {
+	q := NewDynamic[int](4, 16, 10)
+
+	for i := 0; i < 5; i++ {
+		if err := q.TryEnqueue(i); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if q.Cap() < 5 {
+		t.Fatalf("expected ring to have grown to hold 5 items, got cap: %d", q.Cap())
+	}
+	assertDequeueList(t, q, []int{0, 1, 2, 3, 4}, intCompare)
+}
+
+func TestQueue_DynamicBlockingEnqueueWaitsAtHardLimit(t *testing.T) {
+	q := NewDynamic[int](4, 5, 10)
+	for i := 0; i < 5; i++ {
+		if err := q.TryEnqueue(i); err != nil {
+			t.Fatalf("unexpected error priming item %d: %v", i, err)
+		}
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		q.TryDequeue()
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- q.BlockingEnqueue(6)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("BlockingEnqueue did not unblock once a slot freed up")
+	}
+}
+
+func TestQueue_DynamicEnqueueContextWaitsAtHardLimit(t *testing.T) {
+	q := NewDynamic[int](4, 5, 10)
+	for i := 0; i < 5; i++ {
+		if err := q.TryEnqueue(i); err != nil {
+			t.Fatalf("unexpected error priming item %d: %v", i, err)
+		}
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		q.TryDequeue()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := q.EnqueueContext(ctx, 6); err != nil {
+		t.Fatalf("expected EnqueueContext to unblock once a slot freed up, got: %v", err)
+	}
+}
+
+func TestQueue_DynamicEnqueueBatchSpendsCredit(t *testing.T) {
+	q := NewDynamic[int](2, 10, 3)
+
+	// Soft quota is 2 and accrued credit only covers one item past it (see
+	// TestQueue_DynamicSpendsCreditAtSoftQuota), so only 3 of the 4 requested items fit.
+	n, err := q.EnqueueBatch([]int{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("expected EnqueueBatch to admit 3 items before credit ran out, got: %d", n)
+	}
+}
+
+func TestQueue_DynamicTryEnqueueManyReturnsErrNoCreditWhenExhausted(t *testing.T) {
+	q := NewDynamic[int](1, 1, 0)
+	q.TryEnqueue(1)
+
+	if _, err := q.TryEnqueueMany([]int{2}); err != ErrNoCredit {
+		t.Fatalf("expected ErrNoCredit, got: %v", err)
+	}
+}
+
+func TestQueue_DynamicResizePreservesCredit(t *testing.T) {
+	q := NewDynamic[int](2, 10, 5)
+	q.TryEnqueue(1)
+	q.TryEnqueue(2)
+	before := q.Credit()
+
+	if err := q.Resize(6); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if after := q.Credit(); after != before {
+		t.Fatalf("expected Resize to preserve credit %f, got: %f", before, after)
+	}
+}
+
+func TestQueue_UnboundedGrowsInsteadOfFailing(t *testing.T) {
+	q := NewUnbounded[int](2)
+
+	for i := 0; i < 10; i++ {
+		if err := q.TryEnqueue(i); err != nil {
+			t.Fatalf("unexpected error enqueuing %d: %v", i, err)
+		}
+	}
+
+	if q.Cap() < 10 {
+		t.Fatalf("expected ring to have grown to hold 10 items, got cap: %d", q.Cap())
+	}
+
+	want := make([]int, 10)
+	for i := range want {
+		want[i] = i
+	}
+	assertDequeueList(t, q, want, intCompare)
+}
+
+func TestQueue_UnboundedEnqueueBatchGrowsInsteadOfFailing(t *testing.T) {
+	q := NewUnbounded[int](2)
+
+	n, err := q.EnqueueBatch([]int{1, 2, 3, 4, 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 5 {
+		t.Fatalf("expected EnqueueBatch to grow the ring and admit all 5, got: %d", n)
+	}
+	if q.Cap() < 5 {
+		t.Fatalf("expected ring to have grown to hold 5 items, got cap: %d", q.Cap())
+	}
+}
+
+func TestQueue_UnboundedTryEnqueueManyGrowsInsteadOfFailing(t *testing.T) {
+	q := NewUnbounded[int](2)
+
+	n, err := q.TryEnqueueMany([]int{1, 2, 3, 4, 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 5 {
+		t.Fatalf("expected TryEnqueueMany to grow the ring and admit all 5, got: %d", n)
+	}
+	if q.Cap() < 5 {
+		t.Fatalf("expected ring to have grown to hold 5 items, got cap: %d", q.Cap())
+	}
+}
+
+func TestQueue_UnboundedGrowsWithWraparound(t *testing.T) {
+	q := NewUnbounded[int](2)
+	q.TryEnqueue(1)
+	q.TryEnqueue(2)
+	assertDequeueList(t, q, []int{1}, intCompare)
+	q.TryEnqueue(3) // Wraps the 2-slot ring before it ever needs to grow.
+
+	for i := 4; i <= 6; i++ {
+		if err := q.TryEnqueue(i); err != nil {
+			t.Fatalf("unexpected error enqueuing %d: %v", i, err)
+		}
+	}
+
+	assertDequeueList(t, q, []int{2, 3, 4, 5, 6}, intCompare)
+}
+
+func TestQueue_UnboundedRespectsMaxCap(t *testing.T) {
+	q := NewUnbounded[int](2)
+	q.MaxCap(4)
+
+	for i := 0; i < 4; i++ {
+		if err := q.TryEnqueue(i); err != nil {
+			t.Fatalf("unexpected error enqueuing %d: %v", i, err)
+		}
+	}
+
+	if err := q.TryEnqueue(4); err != ErrQueueFull {
+		t.Fatalf("expected ErrQueueFull once MaxCap was reached, got: %v", err)
+	}
+	if q.Cap() != 4 {
+		t.Fatalf("expected cap to stop growing at MaxCap (4), got: %d", q.Cap())
+	}
+}
+
+func TestQueue_UnboundedEnqueueContextGrowsInsteadOfBlocking(t *testing.T) {
+	q := NewUnbounded[int](2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	for i := 0; i < 10; i++ {
+		if err := q.EnqueueContext(ctx, i); err != nil {
+			t.Fatalf("unexpected error enqueuing %d: %v", i, err)
+		}
+	}
+
+	if q.Cap() < 10 {
+		t.Fatalf("expected ring to have grown to hold 10 items, got cap: %d", q.Cap())
+	}
+}
+
+func TestQueue_UnboundedBlockingEnqueueWaitsAtMaxCap(t *testing.T) {
+	q := NewUnbounded[int](2)
+	q.MaxCap(2)
+	q.TryEnqueue(1)
+	q.TryEnqueue(2)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		q.TryDequeue()
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- q.BlockingEnqueue(3)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("BlockingEnqueue did not unblock once a slot freed up at MaxCap")
+	}
+}
+
+func TestQueue_UnboundedShrinkOnDequeueDisabledByDefault(t *testing.T) {
+	q := NewUnbounded[int](2)
+	for i := 0; i < 8; i++ {
+		q.TryEnqueue(i)
+	}
+	grown := q.Cap()
+
+	for i := 0; i < 7; i++ {
+		q.TryDequeue()
+	}
+
+	if q.Cap() != grown {
+		t.Fatalf("expected cap to stay at %d without SetShrinkOnDequeue, got: %d", grown, q.Cap())
+	}
+}
+
+func TestQueue_UnboundedShrinkOnDequeue(t *testing.T) {
+	q := NewUnbounded[int](2)
+	q.SetShrinkOnDequeue(true)
+	for i := 0; i < 8; i++ {
+		q.TryEnqueue(i)
+	}
+	grown := q.Cap()
+
+	for i := 0; i < 7; i++ {
+		if _, err := q.TryDequeue(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if q.Cap() >= grown {
+		t.Fatalf("expected cap to shrink below %d once nearly empty, got: %d", grown, q.Cap())
+	}
+	if q.Cap() < 2 {
+		t.Fatalf("expected cap to never shrink below initialCapacity (2), got: %d", q.Cap())
+	}
+
+	got, err := q.TryDequeue()
+	if err != nil || got != 7 {
+		t.Fatalf("expected 7, got: %d, err: %v", got, err)
+	}
+}
+
+func TestQueue_UnboundedShrinkOnBatchDequeue(t *testing.T) {
+	q := NewUnbounded[int](2)
+	q.SetShrinkOnDequeue(true)
+	for i := 0; i < 8; i++ {
+		q.TryEnqueue(i)
+	}
+	grown := q.Cap()
+
+	if _, err := q.TryDequeueMany(7); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if q.Cap() >= grown {
+		t.Fatalf("expected cap to shrink below %d once nearly empty after a batch dequeue, got: %d", grown, q.Cap())
+	}
+}
+
+func TestQueue_StatsTracksEnqueueDequeueAndPeak(t *testing.T) {
+	q := New[int](2)
+
+	q.TryEnqueue(1)
+	q.TryEnqueue(2)
+	if err := q.TryEnqueue(3); err != ErrQueueFull {
+		t.Fatalf("expected ErrQueueFull, got: %v", err)
+	}
+	q.TryDequeue()
+	q.TryEnqueue(3)
+	q.TryDequeue()
+	q.TryDequeue()
+
+	stats := q.Stats()
+	if stats.Enqueued != 3 {
+		t.Fatalf("expected Enqueued 3, got: %d", stats.Enqueued)
+	}
+	if stats.Dequeued != 3 {
+		t.Fatalf("expected Dequeued 3, got: %d", stats.Dequeued)
+	}
+	if stats.Len != 0 {
+		t.Fatalf("expected Len 0, got: %d", stats.Len)
+	}
+	if stats.PeakLen != 2 {
+		t.Fatalf("expected PeakLen 2, got: %d", stats.PeakLen)
+	}
+}
+
+func TestQueue_StatsTracksBlockedAndResizes(t *testing.T) {
+	q := New[int](1)
+	q.TryEnqueue(1)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		q.TryDequeue()
+	}()
+	if err := q.BlockingEnqueue(2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	q.TryDequeue()
+
+	done := make(chan error, 1)
+	go func() { _, err := q.BlockingDequeue(); done <- err }()
+	time.Sleep(50 * time.Millisecond)
+	q.TryEnqueue(3)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	q.Resize(4)
+
+	stats := q.Stats()
+	if stats.BlockedFull == 0 {
+		t.Fatal("expected BlockedFull to be non-zero")
+	}
+	if stats.BlockedEmpty == 0 {
+		t.Fatal("expected BlockedEmpty to be non-zero")
+	}
+	if stats.Resizes != 1 {
+		t.Fatalf("expected Resizes 1, got: %d", stats.Resizes)
+	}
+}
+
+func TestQueue_WithObserverReceivesEvents(t *testing.T) {
+	var mu sync.Mutex
+	var kinds []EventKind
+
+	q := New[int](1, WithObserver[int](func(ev Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		kinds = append(kinds, ev.Kind)
+	}))
+
+	q.TryEnqueue(1)
+	q.Resize(2)
+	q.TryDequeue()
+	q.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []EventKind{EventEnqueue, EventResize, EventDequeue, EventClose}
+	if len(kinds) != len(want) {
+		t.Fatalf("expected events %v, got: %v", want, kinds)
+	}
+	for i, k := range want {
+		if kinds[i] != k {
+			t.Fatalf("expected events %v, got: %v", want, kinds)
+		}
+	}
+}